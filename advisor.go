@@ -0,0 +1,113 @@
+package capabilities
+
+import (
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+// Suggestion recommends replacing a broad capability with a narrower one.
+type Suggestion struct {
+	// Replace is the broad capability held by the process.
+	Replace Cap
+	// With is the fine-grained alternative.
+	With Cap
+	// Observed is true if the process appears to use the functionality
+	// covered by With, rather than it being a general recommendation.
+	Observed bool
+	// Reason explains the suggestion.
+	Reason string
+}
+
+// alternative is a narrower capability that covers part of a broad one.
+type alternative struct {
+	with   Cap
+	reason string
+}
+
+// capAlternatives lists the fine-grained capabilities that were split out
+// of, or commonly replace, broad capabilities.
+var capAlternatives = map[Cap][]alternative{
+	CapSysAdmin: {
+		{CapBpf, "BPF maps and programs only need CAP_BPF since Linux 5.8"},
+		{CapPerfmon, "performance monitoring only needs CAP_PERFMON since Linux 5.8"},
+		{CapCheckpointRestore, "checkpoint/restore only needs CAP_CHECKPOINT_RESTORE since Linux 5.9"},
+		{CapSyslog, "privileged syslog operations only need CAP_SYSLOG since Linux 2.6.37"},
+		{CapSysNice, "scheduling priority and policy changes need CAP_SYS_NICE"},
+	},
+	CapDacOverride: {
+		{CapDacReadSearch, "read-only access to files and directories only needs CAP_DAC_READ_SEARCH"},
+	},
+	CapNetAdmin: {
+		{CapNetBindService, "binding to ports below 1024 only needs CAP_NET_BIND_SERVICE"},
+		{CapNetRaw, "raw and packet sockets only need CAP_NET_RAW"},
+	},
+}
+
+// Advise suggests fine-grained replacements for the broad capabilities
+// (CAP_SYS_ADMIN, CAP_DAC_OVERRIDE, CAP_NET_ADMIN) in the effective set of
+// pid. The open file descriptors and scheduling parameters of the process
+// are inspected to mark the alternatives it appears to use as Observed.
+// Alternatives the running kernel does not support are omitted.
+func (c *Capabilities) Advise(pid int) ([]Suggestion, error) {
+	effective, err := c.Get(pid, Effective)
+	if err != nil {
+		return nil, err
+	}
+	observed := c.observeUsage(pid)
+	var suggestions []Suggestion
+	for _, broad := range []Cap{CapSysAdmin, CapDacOverride, CapNetAdmin} {
+		if !effective.Contains(broad) {
+			continue
+		}
+		for _, alt := range capAlternatives[broad] {
+			if !KernelSupports(alt.with) {
+				continue
+			}
+			suggestions = append(suggestions, Suggestion{
+				Replace:  broad,
+				With:     alt.with,
+				Observed: observed.Contains(alt.with),
+				Reason:   alt.reason,
+			})
+		}
+	}
+	return suggestions, nil
+}
+
+// observeUsage returns the capabilities pid appears to exercise, judged
+// from its open file descriptors and scheduling parameters. Errors are
+// ignored; an unreadable process simply yields no observations.
+func (c *Capabilities) observeUsage(pid int) CapSet {
+	var used CapSet
+	fdDir := c.procPath(pid, "fd")
+	entries, _ := os.ReadDir(fdDir)
+	for _, e := range entries {
+		target, err := os.Readlink(filepath.Join(fdDir, e.Name()))
+		if err != nil {
+			continue
+		}
+		switch {
+		case strings.HasPrefix(target, "anon_inode:bpf"):
+			used = used.Add(CapBpf)
+		case target == "anon_inode:[perf_event]":
+			used = used.Add(CapPerfmon)
+		}
+	}
+	if stat, err := os.ReadFile(c.procPath(pid, "stat")); err == nil {
+		// Fields after the command name; nice is field 19 and policy is
+		// field 41 of the full line.
+		if i := strings.LastIndexByte(string(stat), ')'); i >= 0 {
+			fields := strings.Fields(string(stat[i+1:]))
+			if len(fields) > 38 {
+				nice, _ := strconv.Atoi(fields[16])
+				policy, _ := strconv.Atoi(fields[38])
+				if nice < 0 || policy == 1 || policy == 2 {
+					used = used.Add(CapSysNice)
+				}
+			}
+		}
+	}
+	return used
+}
@@ -0,0 +1,139 @@
+package capabilities
+
+import (
+	"errors"
+	"fmt"
+
+	"golang.org/x/sys/unix"
+)
+
+// RaiseAmbient adds caps to the ambient set of the calling thread with
+// prctl(PR_CAP_AMBIENT_RAISE), so they are kept across execve(2) of
+// programs without file capabilities. A capability can only be ambient
+// if it is both permitted and inheritable, and raising is refused while
+// SECBIT_NO_CAP_AMBIENT_RAISE is set; both are checked first so that a
+// failing call raises nothing.
+//
+// Capabilities are a per-thread attribute. Callers should hold
+// runtime.LockOSThread.
+func RaiseAmbient(caps ...Cap) error {
+	return withDefault(func(c *Capabilities) error {
+		return c.raiseAmbient(NewCapSet(caps...))
+	})
+}
+
+// PromoteToAmbient makes caps ambient in the calling thread, taking
+// care of the prerequisites: each capability must be permitted, is
+// raised in the inheritable set if it is not already there, and is then
+// raised in the ambient set. A failure is reported as an
+// *AmbientPromotionError naming the step and capability. Everything that
+// can be checked up front is, so that usually nothing changes on
+// failure; if raising an ambient capability fails, the inheritable set
+// keeps the capabilities raised earlier.
+//
+// Capabilities are a per-thread attribute. Callers should hold
+// runtime.LockOSThread.
+func PromoteToAmbient(caps ...Cap) error {
+	return withDefault(func(c *Capabilities) error {
+		return c.promoteToAmbient(NewCapSet(caps...))
+	})
+}
+
+// LowerAmbient removes caps from the ambient set of the calling thread
+// with prctl(PR_CAP_AMBIENT_LOWER).
+func LowerAmbient(caps ...Cap) error {
+	for _, capability := range caps {
+		if !validCap(int(capability)) {
+			return fmt.Errorf("capability %d (last capability is %d): %w", capability, LastCap(), ErrKernelTooOld)
+		}
+		err := unix.Prctl(unix.PR_CAP_AMBIENT, unix.PR_CAP_AMBIENT_LOWER, uintptr(capability), 0, 0)
+		if err != nil {
+			return newProbeError("lower ambient capability", 0, err)
+		}
+	}
+	return nil
+}
+
+// ClearAmbient empties the ambient set of the calling thread with
+// prctl(PR_CAP_AMBIENT_CLEAR_ALL).
+func ClearAmbient() error {
+	err := unix.Prctl(unix.PR_CAP_AMBIENT, unix.PR_CAP_AMBIENT_CLEAR_ALL, 0, 0, 0)
+	return newProbeError("clear ambient capabilities", 0, err)
+}
+
+// raiseAmbient raises caps in the ambient set of the calling thread.
+func (c *Capabilities) raiseAmbient(caps CapSet) error {
+	if caps.IsEmpty() {
+		return nil
+	}
+	for _, capability := range caps.Caps() {
+		if !validCap(int(capability)) {
+			return fmt.Errorf("capability %d (last capability is %d): %w", capability, LastCap(), ErrKernelTooOld)
+		}
+	}
+	s, err := c.GetState(0)
+	if err != nil {
+		return err
+	}
+	if missing := caps.Subtract(s.Permitted); !missing.IsEmpty() {
+		return fmt.Errorf("ambient capabilities %v not in permitted set", missing.Caps())
+	}
+	if missing := caps.Subtract(s.Inheritable); !missing.IsEmpty() {
+		return fmt.Errorf("ambient capabilities %v not in inheritable set", missing.Caps())
+	}
+	bits, err := GetSecurebits()
+	if err == nil && bits&SecbitNoCapAmbientRaise != 0 {
+		return fmt.Errorf("ambient capabilities %v cannot be raised with SECBIT_NO_CAP_AMBIENT_RAISE set", caps.Caps())
+	}
+	for _, capability := range caps.Caps() {
+		err := unix.Prctl(unix.PR_CAP_AMBIENT, unix.PR_CAP_AMBIENT_RAISE, uintptr(capability), 0, 0)
+		if err != nil {
+			return newProbeError("raise ambient capability", 0, err)
+		}
+	}
+	return nil
+}
+
+// promoteToAmbient raises caps in the inheritable and ambient sets of
+// the calling thread.
+func (c *Capabilities) promoteToAmbient(caps CapSet) error {
+	for _, capability := range caps.Caps() {
+		if !validCap(int(capability)) {
+			return fmt.Errorf("capability %d (last capability is %d): %w", capability, LastCap(), ErrKernelTooOld)
+		}
+	}
+	s, err := c.GetState(0)
+	if err != nil {
+		return err
+	}
+	for _, capability := range caps.Caps() {
+		if !s.Permitted.Contains(capability) {
+			return &AmbientPromotionError{Cap: capability, Step: "check permitted", Err: errors.New("not in permitted set")}
+		}
+	}
+	// capset(2) only allows raising an inheritable capability that is
+	// in the bounding set.
+	raise := caps.Subtract(s.Inheritable)
+	for _, capability := range raise.Caps() {
+		if !s.Bounding.Contains(capability) {
+			return &AmbientPromotionError{Cap: capability, Step: "raise inheritable", Err: fmt.Errorf("not in bounding set: %w", unix.EPERM)}
+		}
+	}
+	bits, err := GetSecurebits()
+	if err == nil && bits&SecbitNoCapAmbientRaise != 0 && !caps.IsEmpty() {
+		return &AmbientPromotionError{Cap: caps.Caps()[0], Step: "raise ambient", Err: fmt.Errorf("SECBIT_NO_CAP_AMBIENT_RAISE set: %w", unix.EPERM)}
+	}
+	if !raise.IsEmpty() {
+		s.Inheritable = s.Inheritable.Union(raise)
+		if err := c.store(s); err != nil {
+			return &AmbientPromotionError{Cap: raise.Caps()[0], Step: "raise inheritable", Err: err}
+		}
+	}
+	for _, capability := range caps.Subtract(s.Ambient).Caps() {
+		err := unix.Prctl(unix.PR_CAP_AMBIENT, unix.PR_CAP_AMBIENT_RAISE, uintptr(capability), 0, 0)
+		if err != nil {
+			return &AmbientPromotionError{Cap: capability, Step: "raise ambient", Err: err}
+		}
+	}
+	return nil
+}
@@ -0,0 +1,103 @@
+package capabilities
+
+import (
+	"errors"
+	"path/filepath"
+	"strings"
+)
+
+// FileCapsChange is a change made by ApplyFileCapsTree, or with
+// WithDryRun one that would be made. WatchFileCaps reports observed
+// changes with it as well.
+type FileCapsChange struct {
+	Path string
+	// Old and New are the capabilities before and after the change. Nil
+	// means no capabilities.
+	Old *FileCaps
+	New *FileCaps
+}
+
+// String describes the change in setcap terms, e.g.
+// "/usr/bin/ping: none -> cap_net_raw=ep".
+func (c *FileCapsChange) String() string {
+	text := func(fc *FileCaps) string {
+		if fc == nil {
+			return "none"
+		}
+		return fc.Text()
+	}
+	return c.Path + ": " + text(c.Old) + " -> " + text(c.New)
+}
+
+// WithDryRun makes ApplyFileCapsTree report the changes it would make
+// without writing any attributes.
+func WithDryRun() ScanOption {
+	return func(o *scanOptions) {
+		o.dryRun = true
+	}
+}
+
+// ApplyFileCapsTree sets the capabilities of every regular file below
+// root matching one of patterns to fc, replacing find(1) and setcap(8)
+// pipelines. A nil fc removes capabilities from the matching files.
+//
+// Patterns use the syntax of filepath.Match. A pattern without a path
+// separator is matched against the file name, otherwise against the path
+// relative to root, e.g. "ping" or "bin/*". Files already carrying fc are
+// left alone, and the changes made are returned. WithDryRun,
+// WithCrossDevice and WithErrorHandler apply as for ScanTree.
+func ApplyFileCapsTree(root string, patterns []string, fc *FileCaps, opts ...ScanOption) ([]FileCapsChange, error) {
+	for _, pattern := range patterns {
+		if _, err := filepath.Match(pattern, ""); err != nil {
+			return nil, err
+		}
+	}
+	o := scanOptions{
+		onError: func(string, error) error { return nil },
+	}
+	for _, opt := range opts {
+		opt(&o)
+	}
+	var changes []FileCapsChange
+	err := walkTree(root, &o, func(path string) error {
+		rel, err := filepath.Rel(root, path)
+		if err != nil {
+			return err
+		}
+		if !matchAny(patterns, rel) {
+			return nil
+		}
+		m, err := VerifyFileCaps(path, fc)
+		if err != nil || m.OK() {
+			return err
+		}
+		if !o.dryRun {
+			if fc == nil {
+				err = RemoveFileCaps(path)
+			} else {
+				err = SetFileCaps(path, fc)
+			}
+			if err != nil && !errors.Is(err, ErrNoFileCaps) {
+				return err
+			}
+		}
+		changes = append(changes, FileCapsChange{Path: path, Old: m.Actual, New: fc})
+		return nil
+	})
+	return changes, err
+}
+
+// matchAny reports whether the relative path rel matches one of
+// patterns. Patterns without a separator match the file name only.
+func matchAny(patterns []string, rel string) bool {
+	for _, pattern := range patterns {
+		name := rel
+		if !strings.ContainsRune(pattern, filepath.Separator) {
+			name = filepath.Base(rel)
+		}
+		if ok, _ := filepath.Match(pattern, name); ok {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,49 @@
+package capabilities
+
+import (
+	"encoding/binary"
+	"fmt"
+)
+
+// binaryVersion is the format version written by State.MarshalBinary.
+const binaryVersion = 1
+
+// binarySize is the encoded size of a State: a version byte followed by
+// the five sets as 64-bit little-endian words.
+const binarySize = 1 + 5*8
+
+// MarshalBinary encodes s as a version byte followed by the effective,
+// permitted, inheritable, bounding and ambient sets, each as a 64-bit
+// little-endian word. The encoding is the same on every architecture.
+func (s State) MarshalBinary() ([]byte, error) {
+	b := make([]byte, binarySize)
+	b[0] = binaryVersion
+	for i, set := range []CapSet{s.Effective, s.Permitted, s.Inheritable, s.Bounding, s.Ambient} {
+		binary.LittleEndian.PutUint64(b[1+8*i:], uint64(set))
+	}
+	return b, nil
+}
+
+// UnmarshalBinary decodes the form produced by MarshalBinary.
+func (s *State) UnmarshalBinary(data []byte) error {
+	if len(data) == 0 {
+		return fmt.Errorf("empty capability state encoding")
+	}
+	if data[0] != binaryVersion {
+		return fmt.Errorf("unsupported capability state encoding version %d", data[0])
+	}
+	if len(data) != binarySize {
+		return fmt.Errorf("invalid capability state encoding length %d", len(data))
+	}
+	word := func(i int) CapSet {
+		return CapSet(binary.LittleEndian.Uint64(data[1+8*i:]))
+	}
+	*s = State{
+		Effective:   word(0),
+		Permitted:   word(1),
+		Inheritable: word(2),
+		Bounding:    word(3),
+		Ambient:     word(4),
+	}
+	return nil
+}
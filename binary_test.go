@@ -0,0 +1,86 @@
+package capabilities
+
+import (
+	"bytes"
+	"testing"
+)
+
+func TestBinaryRoundTrip(t *testing.T) {
+	tests := []struct {
+		name    string
+		state   State
+		encoded []byte
+	}{
+		{
+			name: "empty",
+			encoded: []byte{
+				1,
+				0, 0, 0, 0, 0, 0, 0, 0,
+				0, 0, 0, 0, 0, 0, 0, 0,
+				0, 0, 0, 0, 0, 0, 0, 0,
+				0, 0, 0, 0, 0, 0, 0, 0,
+				0, 0, 0, 0, 0, 0, 0, 0,
+			},
+		},
+		{
+			name: "sets",
+			state: State{
+				Effective:   NewCapSet(CapChown),
+				Permitted:   NewCapSet(CapChown, CapNetBindService),
+				Inheritable: NewCapSet(CapNetBindService),
+				Bounding:    NewCapSet(CapChown, CapNetBindService, CapCheckpointRestore),
+				Ambient:     NewCapSet(CapNetBindService),
+			},
+			encoded: []byte{
+				1,
+				0x01, 0, 0, 0, 0, 0, 0, 0,
+				0x01, 0x04, 0, 0, 0, 0, 0, 0,
+				0, 0x04, 0, 0, 0, 0, 0, 0,
+				0x01, 0x04, 0, 0, 0, 0x01, 0, 0,
+				0, 0x04, 0, 0, 0, 0, 0, 0,
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			b, err := tt.state.MarshalBinary()
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !bytes.Equal(b, tt.encoded) {
+				t.Errorf("MarshalBinary = % x, want % x", b, tt.encoded)
+			}
+			var s State
+			if err := s.UnmarshalBinary(b); err != nil {
+				t.Fatal(err)
+			}
+			if s != tt.state {
+				t.Errorf("UnmarshalBinary = %v, want %v", &s, &tt.state)
+			}
+		})
+	}
+}
+
+func TestUnmarshalBinaryErrors(t *testing.T) {
+	valid, err := State{}.MarshalBinary()
+	if err != nil {
+		t.Fatal(err)
+	}
+	tests := []struct {
+		name string
+		data []byte
+	}{
+		{"empty", nil},
+		{"version", append([]byte{2}, valid[1:]...)},
+		{"short", valid[:binarySize-1]},
+		{"long", append(append([]byte{}, valid...), 0)},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var s State
+			if err := s.UnmarshalBinary(tt.data); err == nil {
+				t.Errorf("UnmarshalBinary = %v, want error", &s)
+			}
+		})
+	}
+}
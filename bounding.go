@@ -0,0 +1,89 @@
+package capabilities
+
+import (
+	"fmt"
+
+	"golang.org/x/sys/unix"
+)
+
+// DropBounding removes caps from the bounding set of the calling thread
+// with prctl(PR_CAPBSET_DROP), so that no later execve(2) can regain
+// them, neither through file capabilities nor by running as root. The
+// drop cannot be undone. Capabilities that are already absent are
+// skipped; dropping others requires CAP_SETPCAP in the effective set,
+// which is checked first so that nothing is dropped if it is missing.
+//
+// Only the calling thread is changed; see Thread for what that means in
+// a Go program. DropBoundingAllThreads changes every thread.
+func DropBounding(caps ...Cap) error {
+	return withDefault(func(c *Capabilities) error {
+		return c.dropBounding(NewCapSet(caps...))
+	})
+}
+
+// DropBoundingAllThreads removes caps from the bounding set of every
+// thread of the process, as DropBounding does for the calling thread.
+// Capabilities missing from the bounding set of the calling thread are
+// skipped. It is not available in programs built with cgo, where an
+// error matching unix.ENOTSUP is returned before anything is dropped.
+func DropBoundingAllThreads(caps ...Cap) error {
+	return withDefault(func(c *Capabilities) error {
+		return c.allThreadsDropBounding(NewCapSet(caps...))
+	})
+}
+
+// DropBoundingAll empties the bounding set of the calling thread, as
+// DropBounding does for every capability.
+func DropBoundingAll() error {
+	return withDefault(func(c *Capabilities) error {
+		return c.dropBounding(AllCaps())
+	})
+}
+
+// dropBounding drops caps from the bounding set of the calling thread.
+func (c *Capabilities) dropBounding(caps CapSet) error {
+	return c.dropBoundingWith(caps, func(capability Cap) error {
+		return unix.Prctl(unix.PR_CAPBSET_DROP, uintptr(capability), 0, 0, 0)
+	})
+}
+
+// allThreadsDropBounding drops caps from the bounding set of every
+// thread. Capabilities are skipped if they are missing from the bounding
+// set of the calling thread.
+func (c *Capabilities) allThreadsDropBounding(caps CapSet) error {
+	return c.dropBoundingWith(caps, func(capability Cap) error {
+		return allThreadsPrctl(unix.PR_CAPBSET_DROP, uintptr(capability))
+	})
+}
+
+// dropBoundingWith calls drop for every capability of caps in the
+// bounding set of the calling thread, after checking that CAP_SETPCAP
+// is effective.
+func (c *Capabilities) dropBoundingWith(caps CapSet, drop func(Cap) error) error {
+	for _, capability := range caps.Caps() {
+		if !validCap(int(capability)) {
+			return fmt.Errorf("capability %d (last capability is %d): %w", capability, LastCap(), ErrKernelTooOld)
+		}
+	}
+	bounding, err := c.setFor(0, Bounding)
+	if err != nil {
+		return err
+	}
+	present := caps.Intersect(bounding)
+	if present.IsEmpty() {
+		return nil
+	}
+	effective, err := c.setFor(0, Effective)
+	if err != nil {
+		return err
+	}
+	if !effective.Contains(CapSetpcap) {
+		return fmt.Errorf("drop bounding capabilities %v: CAP_SETPCAP not in effective set: %w", present.Caps(), unix.EPERM)
+	}
+	for _, capability := range present.Caps() {
+		if err := drop(capability); err != nil {
+			return newProbeError("drop bounding capability", 0, err)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,163 @@
+package capabilities
+
+import (
+	"os"
+	"os/exec"
+
+	"golang.org/x/sys/unix"
+)
+
+// brokerEnv marks a process started by StartBroker.
+const brokerEnv = "CAPABILITIES_BROKER_FD"
+
+// Broker is a privileged child process started by StartBroker. It is
+// connected to the parent by a bidirectional stream; Read and Write
+// exchange data with the broker in whatever protocol the program uses.
+type Broker struct {
+	conn *os.File
+	cmd  *exec.Cmd
+}
+
+// StartBroker implements privilege separation by re-executing the
+// current binary as a broker child that holds caps, and then dropping
+// every capability from all threads of the calling process. The parent
+// keeps running without capabilities and asks the broker to perform
+// privileged operations over the returned connection.
+//
+// The child runs with the same arguments. main must call BrokerConn
+// early and, if it reports that the process is the broker, serve
+// requests on the connection instead of running the normal program.
+//
+// The broker is started with a Launcher keeping caps, and caps are
+// raised in its ambient set. If the parent holds CAP_SETPCAP, the
+// bounding set of the broker is reduced to caps and the bounding set of
+// every thread of the parent is emptied, so that a parent running as
+// root cannot regain capabilities by executing a program. Without
+// CAP_SETPCAP, as for a non-root program granted its capabilities
+// through file capabilities, the bounding sets are left unchanged; a
+// parent that still runs as root then regains every capability in its
+// bounding set on execve(2).
+//
+// Dropping the parent's capabilities uses syscall.AllThreadsSyscall and
+// fails in programs built with cgo.
+func StartBroker(caps CapSet) (*Broker, error) {
+	setpcap, err := Has(CapSetpcap)
+	if err != nil {
+		return nil, err
+	}
+	conn, cmd, err := startHelper(brokerEnv, &Launcher{Keep: caps, DropBounding: setpcap}, caps)
+	if err != nil {
+		return nil, err
+	}
+	b := &Broker{conn: conn, cmd: cmd}
+	if setpcap {
+		err = withDefault(func(c *Capabilities) error {
+			return c.allThreadsDropBounding(AllCaps())
+		})
+	}
+	if err == nil {
+		err = dropAllThreads()
+	}
+	if err != nil {
+		b.cmd.Process.Kill()
+		b.Close()
+		return nil, err
+	}
+	return b, nil
+}
+
+// BrokerConn returns the connection to the parent if the calling process
+// is a broker started by StartBroker. The second result is false
+// otherwise.
+func BrokerConn() (*os.File, bool) {
+	return helperConn(brokerEnv)
+}
+
+// Read reads data sent by the broker.
+func (b *Broker) Read(p []byte) (int, error) {
+	return b.conn.Read(p)
+}
+
+// Write sends data to the broker.
+func (b *Broker) Write(p []byte) (int, error) {
+	return b.conn.Write(p)
+}
+
+// Process returns the broker process.
+func (b *Broker) Process() *os.Process {
+	return b.cmd.Process
+}
+
+// Close closes the connection, which the broker sees as end of file,
+// and waits for it to exit.
+func (b *Broker) Close() error {
+	return closeHelper(b.conn, b.cmd)
+}
+
+// startHelper re-executes the current binary with a stream socket as
+// file descriptor 3, announced to the child in the environment variable
+// env. The child is started by launcher with caps in its ambient set.
+func startHelper(env string, launcher *Launcher, caps CapSet) (*os.File, *exec.Cmd, error) {
+	fds, err := unix.Socketpair(unix.AF_UNIX, unix.SOCK_STREAM|unix.SOCK_CLOEXEC, 0)
+	if err != nil {
+		return nil, nil, newError("socketpair", 0, err)
+	}
+	parent := os.NewFile(uintptr(fds[0]), "helper")
+	child := os.NewFile(uintptr(fds[1]), "helper")
+	defer child.Close()
+	cmd := exec.Command("/proc/self/exe", os.Args[1:]...)
+	cmd.Args[0] = os.Args[0]
+	cmd.Env = append(os.Environ(), env+"=3")
+	cmd.Stdin = os.Stdin
+	cmd.Stdout = os.Stdout
+	cmd.Stderr = os.Stderr
+	cmd.ExtraFiles = []*os.File{child}
+	if !caps.IsEmpty() {
+		if err := ConfigureCmd(cmd, caps); err != nil {
+			parent.Close()
+			return nil, nil, err
+		}
+	}
+	if err := launcher.Start(cmd); err != nil {
+		parent.Close()
+		return nil, nil, err
+	}
+	return parent, cmd, nil
+}
+
+// closeHelper closes the connection to a helper and waits for it to exit.
+func closeHelper(conn *os.File, cmd *exec.Cmd) error {
+	err := conn.Close()
+	if werr := cmd.Wait(); err == nil {
+		err = werr
+	}
+	return err
+}
+
+// helperConn returns the stream socket passed by startHelper if env is
+// set and file descriptor 3 is a socket, removing env so that processes
+// started by the helper do not inherit it.
+func helperConn(env string) (*os.File, bool) {
+	if os.Getenv(env) != "3" {
+		return nil, false
+	}
+	os.Unsetenv(env)
+	var st unix.Stat_t
+	if err := unix.Fstat(3, &st); err != nil || st.Mode&unix.S_IFMT != unix.S_IFSOCK {
+		return nil, false
+	}
+	unix.CloseOnExec(3)
+	return os.NewFile(3, "helper"), true
+}
+
+// dropAllThreads clears the effective, permitted, inheritable and
+// ambient sets of every thread.
+func dropAllThreads() error {
+	if err := allThreadsPrctl(unix.PR_CAP_AMBIENT, unix.PR_CAP_AMBIENT_CLEAR_ALL); err != nil {
+		return newProbeError("clear ambient capabilities", 0, err)
+	}
+	if err := allThreadsCapset(&State{}); err != nil {
+		return newError("capset", 0, err)
+	}
+	return nil
+}
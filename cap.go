@@ -0,0 +1,29 @@
+package capabilities
+
+//go:generate go run mkcaps.go -o zcaps.go /usr/include/linux/capability.h
+
+// Cap is a Linux capability number as defined in linux/capability.h. The
+// Cap constants are generated from the kernel header by mkcaps.go.
+type Cap int
+
+// MarshalText encodes c as its canonical name, e.g. "cap_net_admin", so
+// capabilities appear by name in JSON, YAML and TOML documents.
+func (c Cap) MarshalText() ([]byte, error) {
+	return []byte(CapName(c)), nil
+}
+
+// UnmarshalText decodes a capability name in any form understood by
+// ParseCap.
+func (c *Cap) UnmarshalText(text []byte) error {
+	v, err := ParseCap(string(text))
+	if err != nil {
+		return err
+	}
+	*c = v
+	return nil
+}
+
+// String returns the canonical name of c, e.g. "cap_net_admin".
+func (c Cap) String() string {
+	return CapName(c)
+}
@@ -2,7 +2,8 @@ package capabilities
 
 import (
 	"errors"
-	"os"
+	"fmt"
+	"strings"
 
 	"github.com/opcoder0/capabilities/internal"
 	"golang.org/x/sys/unix"
@@ -29,6 +30,35 @@ const (
 	Ambient CapabilitySet = 4
 )
 
+var capabilitySetNames = [...]string{
+	Effective:   "effective",
+	Permitted:   "permitted",
+	Inheritable: "inheritable",
+	Bounding:    "bounding",
+	Ambient:     "ambient",
+}
+
+// String returns the lower case name of the set, e.g. "effective".
+func (s CapabilitySet) String() string {
+	if s >= 0 && int(s) < len(capabilitySetNames) {
+		return capabilitySetNames[s]
+	}
+	return fmt.Sprintf("CapabilitySet(%d)", int(s))
+}
+
+// ParseCapabilitySet returns the CapabilitySet named by name. Names are
+// matched case-insensitively and may be abbreviated to their first
+// letter, e.g. "Effective", "effective" and "e" all return Effective.
+func ParseCapabilitySet(name string) (CapabilitySet, error) {
+	n := strings.ToLower(strings.TrimSpace(name))
+	for s, setName := range capabilitySetNames {
+		if n == setName || (len(n) == 1 && n[0] == setName[0]) {
+			return CapabilitySet(s), nil
+		}
+	}
+	return 0, fmt.Errorf("unknown capability set %q", name)
+}
+
 // Capabilities holds the capabilities header and data
 type Capabilities struct {
 	v3 internal.CapabilityV3
@@ -37,7 +67,8 @@ type Capabilities struct {
 	// Prior to 2.6.25 value is set to 1.
 	// For Linux 2.6.25 added 64-bit capability sets the value is set to 2.
 	// For Linux 2.6.26 and later the value is set to 3.
-	Version int
+	Version  int
+	procRoot string
 }
 
 // Init sets a capability state pointer to the initial capability state.
@@ -45,12 +76,32 @@ type Capabilities struct {
 // Init Capability.Version is set.
 // The initial value of all flags are cleared. The Capabilities value can be
 // used to get or set capabilities.
-func Init() (*Capabilities, error) {
+// If the kernel reports an unknown capability version an
+// *ErrUnsupportedVersion is returned.
+// Options may be passed to force a capability version, use an alternate
+// proc root or skip the initial probe.
+func Init(opts ...Option) (*Capabilities, error) {
+	o := options{procRoot: defaultProcRoot}
+	for _, opt := range opts {
+		opt(&o)
+	}
 	var header unix.CapUserHeader
 	var capability Capabilities
-	err := unix.Capget(&header, nil)
-	if err != nil {
-		return nil, errors.New("unable to probe capability version")
+	capability.procRoot = o.procRoot
+	switch {
+	case o.version != 0:
+		v, ok := headerVersion(o.version)
+		if !ok {
+			return nil, fmt.Errorf("invalid capability version %d", o.version)
+		}
+		header.Version = v
+	case o.noProbe:
+		header.Version = unix.LINUX_CAPABILITY_VERSION_3
+	default:
+		err := unix.Capget(&header, nil)
+		if err != nil {
+			return nil, newProbeError("probe capability version", 0, err)
+		}
 	}
 	switch header.Version {
 	case unix.LINUX_CAPABILITY_VERSION_1:
@@ -58,35 +109,225 @@ func Init() (*Capabilities, error) {
 		capability.v1.Header = header
 	case unix.LINUX_CAPABILITY_VERSION_2:
 		capability.Version = 2
+		capability.v3 = internal.NewCapabilityV3(LastCap())
 		capability.v3.Header = header
 	case unix.LINUX_CAPABILITY_VERSION_3:
 		capability.Version = 3
+		capability.v3 = internal.NewCapabilityV3(LastCap())
 		capability.v3.Header = header
 	default:
-		panic("Unsupported Linux capability version")
+		return nil, &ErrUnsupportedVersion{Version: header.Version}
 	}
 	return &capability, nil
 }
 
+// headerVersion maps a capability version number (1, 2 or 3) to the
+// LINUX_CAPABILITY_VERSION_* value used in the capget/capset header.
+func headerVersion(version int) (uint32, bool) {
+	switch version {
+	case 1:
+		return unix.LINUX_CAPABILITY_VERSION_1, true
+	case 2:
+		return unix.LINUX_CAPABILITY_VERSION_2, true
+	case 3:
+		return unix.LINUX_CAPABILITY_VERSION_3, true
+	}
+	return 0, false
+}
+
 // IsSet returns true if the capability from the capability list
-// (unix.CAP_*) is set for the pid in the capSet CapabilitySet.
+// (unix.CAP_*) is set for the pid in the capSet CapabilitySet. A pid of 0
+// refers to the calling thread.
 // Returns false with nil error if the capability is not set.
-// Returns false with an error if there was an error getting capability.
+// Returns false with an error if there was an error getting capability
+// or if the capability is beyond LastCap (matching ErrKernelTooOld).
+// System call failures are reported as *Error.
 func (c *Capabilities) IsSet(pid, capability int, capSet CapabilitySet) (bool, error) {
-	return c.isSetFor(os.Getpid(), capability, capSet)
+	return c.isSetFor(pid, capability, capSet)
 }
 
 func (c *Capabilities) isSetFor(pid, capability int, capSet CapabilitySet) (bool, error) {
 	if c.Version < 1 || c.Version > 3 {
 		return false, errors.New("invalid capability version")
 	}
+	if !validCap(capability) {
+		return false, fmt.Errorf("capability %d (last capability is %d): %w", capability, LastCap(), ErrKernelTooOld)
+	}
+	if err := c.loadSet(pid, capSet); err != nil {
+		return false, err
+	}
+	return c.isSet(capability, capSet)
+}
+
+// Get returns the capSet CapabilitySet of pid as a CapSet. A pid of 0
+// refers to the calling thread.
+func (c *Capabilities) Get(pid int, capSet CapabilitySet) (CapSet, error) {
+	return c.setFor(pid, capSet)
+}
+
+// setFor returns every capability in the capSet CapabilitySet of pid.
+func (c *Capabilities) setFor(pid int, capSet CapabilitySet) (CapSet, error) {
+	if c.Version < 1 || c.Version > 3 {
+		return 0, errors.New("invalid capability version")
+	}
+	if err := c.loadSet(pid, capSet); err != nil {
+		return 0, err
+	}
+	return c.collect(capSet)
+}
+
+// collect returns the capabilities set in capSet by the last load.
+func (c *Capabilities) collect(capSet CapabilitySet) (CapSet, error) {
+	var caps CapSet
+	for capability := 0; capability <= LastCap(); capability++ {
+		set, err := c.isSet(capability, capSet)
+		if err != nil {
+			return 0, err
+		}
+		if set {
+			caps = caps.Add(Cap(capability))
+		}
+	}
+	return caps, nil
+}
+
+// load reads the effective, permitted and inheritable sets of pid from
+// the kernel. If capget(2) is denied for another process, as some
+// security modules do, the sets are read from /proc/<pid>/status
+// instead.
+func (c *Capabilities) load(pid int) error {
+	if pid != 0 && c.procDir() != defaultProcRoot {
+		return c.loadStatus(pid)
+	}
+	var err error
 	if c.Version == 1 {
 		c.v1.Header.Version = unix.LINUX_CAPABILITY_VERSION_1
 		c.v1.Header.Pid = int32(pid)
-		err := unix.Capget(&c.v1.Header, &c.v1.Data)
-		if err != nil {
-			return false, err
+		err = unix.Capget(&c.v1.Header, &c.v1.Data)
+	} else {
+		if c.Version == 2 {
+			c.v3.Header.Version = unix.LINUX_CAPABILITY_VERSION_2
+		} else if c.Version == 3 {
+			c.v3.Header.Version = unix.LINUX_CAPABILITY_VERSION_3
+		}
+		c.allocV3()
+		c.v3.Header.Pid = int32(pid)
+		err = unix.Capget(&c.v3.Header, &c.v3.Datap[0])
+	}
+	if errors.Is(err, unix.EPERM) && pid != 0 && c.loadStatus(pid) == nil {
+		return nil
+	}
+	return newError("capget", pid, err)
+}
+
+// loadStatus reads the effective, permitted and inheritable sets of pid
+// from the CapEff, CapPrm and CapInh fields of /proc/<pid>/status.
+func (c *Capabilities) loadStatus(pid int) error {
+	s, err := c.ReadProcStatus(pid)
+	if err != nil {
+		return err
+	}
+	if c.Version == 1 {
+		c.v1.Data.Effective = uint32(s.Effective)
+		c.v1.Data.Permitted = uint32(s.Permitted)
+		c.v1.Data.Inheritable = uint32(s.Inheritable)
+		return nil
+	}
+	for i := range c.v3.Datap {
+		shift := uint(32 * i)
+		c.v3.Datap[i].Effective = uint32(s.Effective >> shift)
+		c.v3.Datap[i].Permitted = uint32(s.Permitted >> shift)
+		c.v3.Datap[i].Inheritable = uint32(s.Inheritable >> shift)
+	}
+	return nil
+}
+
+// loadSet reads the data needed to answer queries about capSet.
+func (c *Capabilities) loadSet(pid int, capSet CapabilitySet) error {
+	if err := c.load(pid); err != nil {
+		return err
+	}
+	if c.Version == 1 {
+		return nil
+	}
+	switch capSet {
+	case Bounding:
+		return c.loadBounding(pid)
+	case Ambient:
+		return c.loadAmbient(pid)
+	}
+	return nil
+}
+
+// loadBounding reads the bounding set of pid. The bounding set of the
+// calling process is read with prctl(PR_CAPBSET_READ); other processes
+// are read from the CapBnd field of /proc/<pid>/status.
+func (c *Capabilities) loadBounding(pid int) error {
+	return c.loadPrctlSet(pid, "read bounding set", "CapBnd", c.v3.Bounds, false, func(capability int) (int, error) {
+		return unix.PrctlRetInt(unix.PR_CAPBSET_READ, uintptr(capability), 0, 0, 0)
+	})
+}
+
+// loadAmbient reads the ambient set of pid. The ambient set of the
+// calling process is read with prctl(PR_CAP_AMBIENT_IS_SET); other
+// processes are read from the CapAmb field of /proc/<pid>/status.
+// Kernels before Linux 4.3 have no ambient set; it is reported as empty.
+func (c *Capabilities) loadAmbient(pid int) error {
+	return c.loadPrctlSet(pid, "read ambient set", "CapAmb", c.v3.Ambient, true, func(capability int) (int, error) {
+		return unix.PrctlRetInt(unix.PR_CAP_AMBIENT, unix.PR_CAP_AMBIENT_IS_SET, uintptr(capability), 0, 0)
+	})
+}
+
+// loadPrctlSet fills words either by calling isSet for every capability
+// when pid is the calling process, or from field of /proc/<pid>/status.
+// If optional is set, a kernel without support for the set (isSet fails
+// with EINVAL, or the field is missing) leaves words empty.
+func (c *Capabilities) loadPrctlSet(pid int, op, field string, words []uint32, optional bool, isSet func(int) (int, error)) error {
+	if c.isSelf(pid) {
+		for i := range words {
+			words[i] = 0
+		}
+		for capability := 0; capability <= LastCap(); capability++ {
+			set, err := isSet(capability)
+			if optional && errors.Is(err, unix.EINVAL) {
+				for i := range words {
+					words[i] = 0
+				}
+				return nil
+			}
+			if err != nil {
+				return newProbeError(op, pid, err)
+			}
+			if set == 1 {
+				words[capability/32] |= 1 << uint(capability%32)
+			}
 		}
+		return nil
+	}
+	mask, err := c.readStatusField(pid, field)
+	if optional && errors.Is(err, errFieldNotFound) {
+		mask = ""
+		err = nil
+	}
+	if err != nil {
+		return newError(op, pid, err)
+	}
+	return parseHexWords(mask, words)
+}
+
+// allocV3 allocates the v3 data words if the Capabilities value was not
+// created by Init.
+func (c *Capabilities) allocV3() {
+	if len(c.v3.Datap) == 0 {
+		header := c.v3.Header
+		c.v3 = internal.NewCapabilityV3(LastCap())
+		c.v3.Header = header
+	}
+}
+
+// isSet tests capability against the data read by the last load.
+func (c *Capabilities) isSet(capability int, capSet CapabilitySet) (bool, error) {
+	if c.Version == 1 {
 		switch capSet {
 		case Effective:
 			return c.v1.IsEffectiveSet(capability), nil
@@ -94,20 +335,12 @@ func (c *Capabilities) isSetFor(pid, capability int, capSet CapabilitySet) (bool
 			return c.v1.IsInheritableSet(capability), nil
 		case Permitted:
 			return c.v1.IsPermittedSet(capability), nil
+		case Bounding, Ambient:
+			return false, &ErrSetNotSupported{Set: capSet, Version: c.Version}
 		default:
 			return false, errors.New("invalid capability set for capability v1")
 		}
 	}
-	if c.Version == 2 {
-		c.v3.Header.Version = unix.LINUX_CAPABILITY_VERSION_2
-	} else if c.Version == 3 {
-		c.v3.Header.Version = unix.LINUX_CAPABILITY_VERSION_3
-	}
-	c.v3.Header.Pid = int32(pid)
-	err := unix.Capget(&c.v3.Header, &c.v3.Datap[0])
-	if err != nil {
-		return false, err
-	}
 	switch capSet {
 	case Effective:
 		return c.v3.IsEffectiveSet(capability), nil
@@ -0,0 +1,83 @@
+package capabilities
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"golang.org/x/sys/unix"
+)
+
+// Capable reports whether the calling thread holds capability over the
+// resources of process target, mirroring the kernel's ns_capable()
+// check against the user namespace of target (a target of 0 means the
+// caller's own user namespace, e.g. host resources for a process on the
+// host). The kernel walks from the target's namespace towards the
+// caller's:
+//
+//   - in the caller's own namespace the capability must be in the
+//     effective set; an effective user ID of 0 alone grants nothing
+//   - in a child namespace created by the caller's effective user ID
+//     the caller holds every capability
+//   - a namespace that does not descend from the caller's is never
+//     controlled, whatever the caller holds
+//
+// The namespace hierarchy is read with the NS_GET_PARENT and
+// NS_GET_OWNER_UID ioctls (Linux 4.9 and later). LSMs such as SELinux
+// and AppArmor may deny an operation the capability check allows.
+func Capable(capability Cap, target int) (bool, error) {
+	if !validCap(int(capability)) {
+		return false, fmt.Errorf("capability %d (last capability is %d): %w", capability, LastCap(), ErrKernelTooOld)
+	}
+	self := filepath.Join(defaultProcRoot, "thread-self", "ns", "user")
+	path := self
+	var effective CapSet
+	err := withDefault(func(c *Capabilities) error {
+		if target != 0 {
+			path = c.procPath(target, "ns/user")
+		}
+		var err error
+		effective, err = c.setFor(0, Effective)
+		return err
+	})
+	if err != nil {
+		return false, err
+	}
+	var st unix.Stat_t
+	if err := unix.Stat(self, &st); err != nil {
+		return false, &os.PathError{Op: "stat", Path: self, Err: err}
+	}
+	callerNS := st.Ino
+	fd, err := unix.Open(path, unix.O_RDONLY|unix.O_CLOEXEC, 0)
+	if err != nil {
+		return false, newError("open user namespace", target, err)
+	}
+	defer func() { unix.Close(fd) }()
+	euid := uint32(os.Geteuid())
+	for {
+		if err := unix.Fstat(fd, &st); err != nil {
+			return false, newError("stat user namespace", target, err)
+		}
+		if st.Ino == callerNS {
+			return effective.Contains(capability), nil
+		}
+		owner, err := unix.IoctlGetUint32(fd, unix.NS_GET_OWNER_UID)
+		if err != nil {
+			return false, newError("get user namespace owner", target, err)
+		}
+		parent, err := unix.IoctlRetInt(fd, unix.NS_GET_PARENT)
+		if err != nil {
+			// The initial namespace, or a parent outside the caller's
+			// namespace: the target does not descend from it.
+			return false, nil
+		}
+		unix.Close(fd)
+		fd = parent
+		if err := unix.Fstat(fd, &st); err != nil {
+			return false, newError("stat user namespace", target, err)
+		}
+		if st.Ino == callerNS && owner == euid {
+			return true, nil
+		}
+	}
+}
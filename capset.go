@@ -0,0 +1,98 @@
+package capabilities
+
+import (
+	"math/bits"
+)
+
+// CapSet is a set of capabilities stored as a bit mask where bit n
+// represents capability n. The zero value is the empty set. CapSet is a
+// value type; all operations return a new set. CapSet covers capabilities
+// 0-63. On a kernel with more capabilities, as reported by
+// CapSetTruncated, CapSet values, and so State, leave out capabilities
+// above 63, and applying a State clears them.
+type CapSet uint64
+
+// maxCapSetCap is the highest capability a CapSet can hold.
+const maxCapSetCap = 63
+
+// CapSetTruncated returns true if the running kernel supports
+// capabilities above 63, which a CapSet cannot hold. The internal storage
+// is sized from cap_last_cap, so methods taking a capability number, such
+// as Capabilities.IsSet, still handle them.
+func CapSetTruncated() bool {
+	return LastCap() > maxCapSetCap
+}
+
+// NewCapSet returns a set containing caps. Capabilities outside the range
+// 0-63 are ignored.
+func NewCapSet(caps ...Cap) CapSet {
+	var s CapSet
+	return s.Add(caps...)
+}
+
+// Add returns s with caps added.
+func (s CapSet) Add(caps ...Cap) CapSet {
+	for _, c := range caps {
+		if c >= 0 && c < 64 {
+			s |= 1 << uint(c)
+		}
+	}
+	return s
+}
+
+// Remove returns s with caps removed.
+func (s CapSet) Remove(caps ...Cap) CapSet {
+	for _, c := range caps {
+		if c >= 0 && c < 64 {
+			s &^= 1 << uint(c)
+		}
+	}
+	return s
+}
+
+// Contains returns true if c is in s.
+func (s CapSet) Contains(c Cap) bool {
+	if c < 0 || c >= 64 {
+		return false
+	}
+	return s&(1<<uint(c)) != 0
+}
+
+// ContainsAll returns true if every capability in o is also in s.
+func (s CapSet) ContainsAll(o CapSet) bool {
+	return s&o == o
+}
+
+// Union returns the capabilities that are in s or o.
+func (s CapSet) Union(o CapSet) CapSet {
+	return s | o
+}
+
+// Intersect returns the capabilities that are in both s and o.
+func (s CapSet) Intersect(o CapSet) CapSet {
+	return s & o
+}
+
+// Subtract returns the capabilities in s that are not in o.
+func (s CapSet) Subtract(o CapSet) CapSet {
+	return s &^ o
+}
+
+// IsEmpty returns true if s contains no capabilities.
+func (s CapSet) IsEmpty() bool {
+	return s == 0
+}
+
+// Len returns the number of capabilities in s.
+func (s CapSet) Len() int {
+	return bits.OnesCount64(uint64(s))
+}
+
+// Caps returns the capabilities in s in ascending order.
+func (s CapSet) Caps() []Cap {
+	caps := make([]Cap, 0, s.Len())
+	for v := uint64(s); v != 0; v &= v - 1 {
+		caps = append(caps, Cap(bits.TrailingZeros64(v)))
+	}
+	return caps
+}
@@ -0,0 +1,90 @@
+package capabilities
+
+import (
+	"fmt"
+	"os/exec"
+	"syscall"
+)
+
+// ConfigureCmd arranges for the child started by cmd to run with caps in
+// its ambient set, and therefore in its effective and permitted sets
+// after execve(2) of a program without file capabilities. This is how a
+// supervisor gives a non-root child, e.g. one started with
+// SysProcAttr.Credential, capabilities such as CAP_NET_BIND_SERVICE.
+//
+// The child raises caps in its permitted, inheritable and ambient sets
+// between fork and exec, so the calling process must hold them in its
+// permitted and bounding sets, and SECBIT_NO_CAP_AMBIENT_RAISE must not
+// be set. These prerequisites are checked here so that a misconfiguration
+// is reported before cmd is started rather than as an EPERM from Start.
+// Capabilities already listed in SysProcAttr.AmbientCaps are kept.
+func ConfigureCmd(cmd *exec.Cmd, caps CapSet) error {
+	s, err := GetState()
+	if err != nil {
+		return err
+	}
+	if missing := caps.Subtract(s.Permitted.Intersect(s.Bounding)); !missing.IsEmpty() {
+		return fmt.Errorf("ambient capabilities %v not in permitted and bounding sets", missing.Caps())
+	}
+	bits, err := GetSecurebits()
+	if err == nil && bits&SecbitNoCapAmbientRaise != 0 && !caps.IsEmpty() {
+		return fmt.Errorf("ambient capabilities %v cannot be raised with SECBIT_NO_CAP_AMBIENT_RAISE set", caps.Caps())
+	}
+	if cmd.SysProcAttr == nil {
+		cmd.SysProcAttr = &syscall.SysProcAttr{}
+	}
+	var existing CapSet
+	for _, c := range cmd.SysProcAttr.AmbientCaps {
+		existing = existing.Add(Cap(c))
+	}
+	for _, c := range caps.Subtract(existing).Caps() {
+		cmd.SysProcAttr.AmbientCaps = append(cmd.SysProcAttr.AmbientCaps, uintptr(c))
+	}
+	return nil
+}
+
+// Command is like exec.Command but the child runs with caps in its
+// ambient set, as configured by ConfigureCmd.
+func Command(caps CapSet, name string, arg ...string) (*exec.Cmd, error) {
+	cmd := exec.Command(name, arg...)
+	if err := ConfigureCmd(cmd, caps); err != nil {
+		return nil, err
+	}
+	return cmd, nil
+}
+
+// PrepareForExec makes caps survive the next execve(2) of the calling
+// thread by raising them in its inheritable and ambient sets, in that
+// order, as the kernel requires. The capabilities must already be
+// permitted. The returned function restores the previous inheritable
+// and ambient sets, e.g. after the exec failed.
+//
+// Capabilities are a per-thread attribute. Callers should hold
+// runtime.LockOSThread from PrepareForExec until the exec, and use
+// ConfigureCmd instead when starting a child with os/exec.
+func PrepareForExec(caps ...Cap) (restore func() error, err error) {
+	want := NewCapSet(caps...)
+	var old *State
+	err = withDefault(func(c *Capabilities) error {
+		var err error
+		if old, err = c.GetState(0); err != nil {
+			return err
+		}
+		if missing := want.Subtract(old.Permitted); !missing.IsEmpty() {
+			return fmt.Errorf("capabilities %v not in permitted set", missing.Caps())
+		}
+		s := *old
+		s.Inheritable = s.Inheritable.Union(want)
+		s.Ambient = s.Ambient.Union(want)
+		return c.apply(&s)
+	})
+	if err != nil {
+		return nil, err
+	}
+	restore = func() error {
+		return withDefault(func(c *Capabilities) error {
+			return c.apply(old)
+		})
+	}
+	return restore, nil
+}
@@ -0,0 +1,211 @@
+package capabilities
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"syscall"
+	"unsafe"
+
+	"github.com/opcoder0/capabilities/internal"
+	"golang.org/x/sys/unix"
+)
+
+// ProcEventType identifies a process event reported by the proc
+// connector.
+type ProcEventType int
+
+const (
+	// ProcFork is reported when a process forks a new process.
+	ProcFork ProcEventType = iota + 1
+	// ProcExec is reported when a process calls execve(2).
+	ProcExec
+	// ProcExit is reported when a process exits.
+	ProcExit
+)
+
+// String returns "fork", "exec" or "exit".
+func (t ProcEventType) String() string {
+	switch t {
+	case ProcFork:
+		return "fork"
+	case ProcExec:
+		return "exec"
+	case ProcExit:
+		return "exit"
+	}
+	return fmt.Sprintf("ProcEventType(%d)", int(t))
+}
+
+// ProcEvent is a process event received by ProcEvents.
+type ProcEvent struct {
+	Type ProcEventType
+	// Pid is the process the event is about; for ProcFork it is the new
+	// child.
+	Pid int
+	// PPid is the parent process for ProcFork and ProcExit events. It is
+	// 0 for ProcExit events on kernels before 4.18, which do not report
+	// the parent.
+	PPid int
+	// ExitCode is the wait(2) status of a ProcExit event.
+	ExitCode int
+	// State holds the capabilities of the process, read as soon as a
+	// ProcFork or ProcExec event arrives. It is nil for ProcExit events
+	// and when the process exited before it could be read.
+	State *State
+}
+
+// ProcEvents subscribes to the kernel proc connector and delivers fork,
+// exec and exit events of every process on the host as they happen, each
+// fork and exec event carrying the capabilities of the process at that
+// point. Unlike polling the proc root, short-lived processes are not
+// missed. Events of threads other than the thread group leader are not
+// reported.
+//
+// The proc connector requires CAP_NET_ADMIN in the initial user and
+// network namespaces. The channel is closed when ctx is done. If the
+// kernel drops events because they are not consumed fast enough, the
+// lost events are skipped.
+func (c *Capabilities) ProcEvents(ctx context.Context) (<-chan ProcEvent, error) {
+	fd, err := unix.Socket(unix.AF_NETLINK, unix.SOCK_DGRAM|unix.SOCK_CLOEXEC|unix.SOCK_NONBLOCK, unix.NETLINK_CONNECTOR)
+	if err != nil {
+		return nil, newError("socket", 0, err)
+	}
+	addr := &unix.SockaddrNetlink{Family: unix.AF_NETLINK, Groups: internal.CnIdxProc}
+	if err := unix.Bind(fd, addr); err != nil {
+		unix.Close(fd)
+		return nil, newError("bind proc connector", 0, err)
+	}
+	if err := procCnControl(fd, internal.ProcCnMcastListen); err != nil {
+		unix.Close(fd)
+		return nil, newError("listen proc connector", 0, err)
+	}
+	f := os.NewFile(uintptr(fd), "proc connector")
+	events := make(chan ProcEvent)
+	done := make(chan struct{})
+	go func() {
+		select {
+		case <-ctx.Done():
+		case <-done:
+		}
+		f.Close()
+	}()
+	go func() {
+		defer close(events)
+		defer close(done)
+		buf := make([]byte, os.Getpagesize())
+		for {
+			n, err := f.Read(buf)
+			if err != nil {
+				if errors.Is(err, unix.ENOBUFS) {
+					continue
+				}
+				return
+			}
+			msgs, err := syscall.ParseNetlinkMessage(buf[:n])
+			if err != nil {
+				continue
+			}
+			for _, m := range msgs {
+				ev, ok := c.decodeProcEvent(m.Data)
+				if !ok {
+					continue
+				}
+				select {
+				case events <- ev:
+				case <-ctx.Done():
+					return
+				}
+			}
+		}
+	}()
+	return events, nil
+}
+
+// procCnControl sends a PROC_CN_MCAST_LISTEN or PROC_CN_MCAST_IGNORE
+// request to the proc connector.
+func procCnControl(fd int, op uint32) error {
+	const (
+		hdrLen = unix.SizeofNlMsghdr
+		cnLen  = int(unsafe.Sizeof(internal.CnMsg{}))
+	)
+	buf := make([]byte, hdrLen+cnLen+4)
+	hdr := unix.NlMsghdr{
+		Len:  uint32(len(buf)),
+		Type: unix.NLMSG_DONE,
+		Pid:  uint32(os.Getpid()),
+	}
+	cn := internal.CnMsg{Idx: internal.CnIdxProc, Val: internal.CnValProc, Len: 4}
+	copy(buf, hostBytes(unsafe.Pointer(&hdr), hdrLen))
+	copy(buf[hdrLen:], hostBytes(unsafe.Pointer(&cn), cnLen))
+	copy(buf[hdrLen+cnLen:], hostBytes(unsafe.Pointer(&op), 4))
+	return unix.Sendto(fd, buf, 0, &unix.SockaddrNetlink{Family: unix.AF_NETLINK})
+}
+
+// decodeProcEvent decodes the cn_msg payload of a proc connector
+// message. The second result is false for events that are not reported.
+func (c *Capabilities) decodeProcEvent(b []byte) (ProcEvent, bool) {
+	var (
+		ev  ProcEvent
+		cn  internal.CnMsg
+		hdr internal.ProcEventHeader
+	)
+	cnLen := int(unsafe.Sizeof(cn))
+	hdrLen := int(unsafe.Sizeof(hdr))
+	if !fromHostBytes(b, unsafe.Pointer(&cn), cnLen) || cn.Idx != internal.CnIdxProc || cn.Val != internal.CnValProc {
+		return ev, false
+	}
+	b = b[cnLen:]
+	if !fromHostBytes(b, unsafe.Pointer(&hdr), hdrLen) {
+		return ev, false
+	}
+	data := b[hdrLen:]
+	switch hdr.What {
+	case internal.ProcEventFork:
+		var fork internal.ForkProcEvent
+		if !fromHostBytes(data, unsafe.Pointer(&fork), int(unsafe.Sizeof(fork))) || fork.ChildPid != fork.ChildTgid {
+			return ev, false
+		}
+		ev = ProcEvent{Type: ProcFork, Pid: int(fork.ChildTgid), PPid: int(fork.ParentTgid)}
+	case internal.ProcEventExec:
+		var exec internal.ExecProcEvent
+		if !fromHostBytes(data, unsafe.Pointer(&exec), int(unsafe.Sizeof(exec))) {
+			return ev, false
+		}
+		ev = ProcEvent{Type: ProcExec, Pid: int(exec.ProcessTgid)}
+	case internal.ProcEventExit:
+		var exit internal.ExitProcEvent
+		// Kernels before 4.18 send the event without the parent
+		// fields, which are then left 0.
+		size := int(unsafe.Sizeof(exit))
+		if len(data) < size {
+			size = int(unsafe.Offsetof(exit.ParentPid))
+		}
+		if !fromHostBytes(data, unsafe.Pointer(&exit), size) || exit.ProcessPid != exit.ProcessTgid {
+			return ev, false
+		}
+		return ProcEvent{Type: ProcExit, Pid: int(exit.ProcessTgid), PPid: int(exit.ParentTgid), ExitCode: int(exit.ExitCode)}, true
+	default:
+		return ev, false
+	}
+	if s, err := c.ReadProcStatus(ev.Pid); err == nil {
+		ev.State = s
+	}
+	return ev, true
+}
+
+// hostBytes returns the size bytes of the value at p in host byte order.
+func hostBytes(p unsafe.Pointer, size int) []byte {
+	return unsafe.Slice((*byte)(p), size)
+}
+
+// fromHostBytes copies size bytes from b to the value at p. It returns
+// false if b is too short.
+func fromHostBytes(b []byte, p unsafe.Pointer, size int) bool {
+	if len(b) < size {
+		return false
+	}
+	copy(hostBytes(p, size), b)
+	return true
+}
@@ -0,0 +1,92 @@
+package capabilities
+
+import (
+	"encoding/json"
+	"os"
+)
+
+// Config describes a capability state declaratively as lists of
+// capability names. Cap implements encoding.TextMarshaler, so the lists
+// read and write as names with encoding/json and with YAML libraries such
+// as gopkg.in/yaml.v3:
+//
+//	effective: [cap_net_bind_service]
+//	permitted: [cap_net_bind_service]
+//
+// A missing (nil) Bounding list keeps every capability in the bounding
+// set; an empty list clears it. For that reason Bounding is always
+// written, even when empty.
+type Config struct {
+	Effective   []Cap `json:"effective,omitempty" yaml:"effective,omitempty"`
+	Permitted   []Cap `json:"permitted,omitempty" yaml:"permitted,omitempty"`
+	Inheritable []Cap `json:"inheritable,omitempty" yaml:"inheritable,omitempty"`
+	Ambient     []Cap `json:"ambient,omitempty" yaml:"ambient,omitempty"`
+	Bounding    []Cap `json:"bounding" yaml:"bounding"`
+}
+
+// UnmarshalFunc decodes data into v, e.g. json.Unmarshal or yaml.Unmarshal.
+type UnmarshalFunc func(data []byte, v interface{}) error
+
+// MarshalFunc encodes v, e.g. json.Marshal or yaml.Marshal.
+type MarshalFunc func(v interface{}) ([]byte, error)
+
+// NewConfig returns the Config describing s.
+func NewConfig(s *State) *Config {
+	return &Config{
+		Effective:   s.Effective.Caps(),
+		Permitted:   s.Permitted.Caps(),
+		Inheritable: s.Inheritable.Caps(),
+		Ambient:     s.Ambient.Caps(),
+		Bounding:    s.Bounding.Caps(),
+	}
+}
+
+// State returns the validated State described by c.
+func (c *Config) State() (*State, error) {
+	s := State{
+		Effective:   NewCapSet(c.Effective...),
+		Permitted:   NewCapSet(c.Permitted...),
+		Inheritable: NewCapSet(c.Inheritable...),
+		Ambient:     NewCapSet(c.Ambient...),
+		Bounding:    NewCapSet(c.Bounding...),
+	}
+	if c.Bounding == nil {
+		s.Bounding = AllCaps()
+	}
+	if err := s.Validate(); err != nil {
+		return nil, err
+	}
+	return &s, nil
+}
+
+// LoadConfig reads a Config from path using unmarshal. A nil unmarshal
+// reads JSON; pass yaml.Unmarshal to read YAML.
+func LoadConfig(path string, unmarshal UnmarshalFunc) (*Config, error) {
+	if unmarshal == nil {
+		unmarshal = json.Unmarshal
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var c Config
+	if err := unmarshal(data, &c); err != nil {
+		return nil, err
+	}
+	return &c, nil
+}
+
+// Save writes c to path using marshal. A nil marshal writes indented
+// JSON; pass yaml.Marshal to write YAML.
+func (c *Config) Save(path string, marshal MarshalFunc) error {
+	if marshal == nil {
+		marshal = func(v interface{}) ([]byte, error) {
+			return json.MarshalIndent(v, "", "  ")
+		}
+	}
+	data, err := marshal(c)
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(path, data, 0644)
+}
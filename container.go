@@ -0,0 +1,82 @@
+package capabilities
+
+import (
+	"fmt"
+	"os"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// containerIDPattern matches the 64 hex digit container IDs used by
+// Docker, containerd and CRI-O in cgroup paths, e.g.
+// /docker/<id>, /system.slice/docker-<id>.scope or
+// /kubepods/.../cri-containerd-<id>.scope.
+var containerIDPattern = regexp.MustCompile(`[0-9a-f]{64}`)
+
+// ContainerInit returns the pid of the init process of the container
+// with the given ID, found through the cgroup paths in
+// /proc/<pid>/cgroup. The ID may be abbreviated as long as it is
+// unambiguous, as with docker ps. The init process is the process of the
+// container whose parent is outside it. The returned error matches
+// ErrNoSuchProcess if no process belongs to the container.
+func (c *Capabilities) ContainerInit(id string) (int, error) {
+	id = strings.ToLower(strings.TrimSpace(id))
+	if id == "" {
+		return 0, fmt.Errorf("empty container ID")
+	}
+	entries, err := os.ReadDir(c.procDir())
+	if err != nil {
+		return 0, err
+	}
+	var fullID string
+	members := make(map[int]bool)
+	for _, e := range entries {
+		pid, err := strconv.Atoi(e.Name())
+		if err != nil {
+			continue
+		}
+		cgroup, err := os.ReadFile(c.procPath(pid, "cgroup"))
+		if err != nil {
+			continue
+		}
+		for _, candidate := range containerIDPattern.FindAllString(string(cgroup), -1) {
+			if !strings.HasPrefix(candidate, id) {
+				continue
+			}
+			if fullID != "" && candidate != fullID {
+				return 0, fmt.Errorf("container ID %q is ambiguous", id)
+			}
+			fullID = candidate
+			members[pid] = true
+			break
+		}
+	}
+	var inits []int
+	for pid := range members {
+		ppid, err := c.readStatusField(pid, "PPid")
+		if err != nil {
+			continue
+		}
+		if n, err := strconv.Atoi(ppid); err == nil && !members[n] {
+			inits = append(inits, pid)
+		}
+	}
+	if len(inits) == 0 {
+		return 0, fmt.Errorf("container %q: %w", id, ErrNoSuchProcess)
+	}
+	sort.Ints(inits)
+	return inits[0], nil
+}
+
+// ContainerState returns the capability state of the init process of
+// the container with the given ID, answering "what capabilities does
+// this container actually have" in one call.
+func (c *Capabilities) ContainerState(id string) (*State, error) {
+	pid, err := c.ContainerInit(id)
+	if err != nil {
+		return nil, err
+	}
+	return c.GetState(pid)
+}
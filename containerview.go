@@ -0,0 +1,113 @@
+package capabilities
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"text/tabwriter"
+)
+
+// namespaceTypes are the namespaces compared by CompareContainerView,
+// named as in /proc/<pid>/ns.
+var namespaceTypes = []string{"mnt", "net", "pid", "ipc", "uts", "cgroup"}
+
+// ContainerView puts the capabilities of a containerized process as seen
+// inside its namespaces next to what they amount to on the host.
+type ContainerView struct {
+	// NamespacedState holds the state seen inside the container and the
+	// capabilities effective towards the caller's user namespace.
+	NamespacedState
+	// SharedNamespaces are the namespaces, named as in /proc/<pid>/ns,
+	// the process shares with the caller.
+	SharedNamespaces []string
+	// Warnings describe the ways the privileges inside the container
+	// translate to power on the host.
+	Warnings []string
+}
+
+// CompareContainerView returns the inside and host views of the
+// capabilities of pid, a process running in a container, and flags
+// cases where its privileges are real on the host: a container sharing
+// the host user namespace, user namespace root mapped to host root, and
+// file capabilities applying to host files on shared mounts. The
+// namespaces of the caller are read from self under the same proc root
+// as those of pid.
+func (c *Capabilities) CompareContainerView(pid int) (*ContainerView, error) {
+	st, err := c.GetNamespacedState(pid)
+	if err != nil {
+		return nil, err
+	}
+	v := &ContainerView{NamespacedState: *st}
+	for _, ns := range namespaceTypes {
+		theirs, err := os.Readlink(c.procPath(pid, filepath.Join("ns", ns)))
+		if err != nil {
+			continue
+		}
+		ours, err := os.Readlink(filepath.Join(c.procDir(), "self", "ns", ns))
+		if err == nil && ours == theirs {
+			v.SharedNamespaces = append(v.SharedNamespaces, ns)
+		}
+	}
+	effective := st.State.Effective
+	if st.Relation == SameUserNS {
+		if !effective.IsEmpty() {
+			v.warn("shares the host user namespace: effective capabilities %s are host capabilities", summarizeSet(effective))
+		}
+		if v.shares("mnt") && effective.Contains(CapSysAdmin) {
+			v.warn("cap_sys_admin with the host mount namespace can remount host file systems")
+		}
+		return v, nil
+	}
+	if rootID, ok := st.UserNS.HostUID(0); ok && rootID == 0 {
+		v.warn("root of the user namespace is host root (uid 0)")
+	}
+	if fileCaps := effective.Intersect(FileAccessCaps); !fileCaps.IsEmpty() && len(st.UserNS.UIDMap) > 0 {
+		where := "on bind-mounted host directories"
+		if v.shares("mnt") {
+			where = "in the host mount namespace"
+		}
+		v.warn("%s apply to host files owned by mapped IDs %s", summarizeSet(fileCaps), where)
+	}
+	return v, nil
+}
+
+// shares returns true if the process shares namespace ns with the caller.
+func (v *ContainerView) shares(ns string) bool {
+	for _, shared := range v.SharedNamespaces {
+		if shared == ns {
+			return true
+		}
+	}
+	return false
+}
+
+func (v *ContainerView) warn(format string, args ...interface{}) {
+	v.Warnings = append(v.Warnings, fmt.Sprintf(format, args...))
+}
+
+// String renders the views side by side, followed by the warnings:
+//
+//	SET        INSIDE  HOST
+//	effective  all     none
+//	...
+//	warning: root of the user namespace is host root (uid 0)
+func (v *ContainerView) String() string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "pid %d, %v user namespace %d, shared namespaces: %s\n",
+		v.Pid, v.Relation, v.UserNS.Inode, strings.Join(v.SharedNamespaces, ","))
+	tw := tabwriter.NewWriter(&b, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(tw, "SET\tINSIDE\tHOST")
+	for _, set := range []CapabilitySet{Effective, Permitted, Inheritable, Bounding, Ambient} {
+		host := v.State.Set(set)
+		if v.Relation != SameUserNS && v.Relation != AncestorUserNS {
+			host = 0
+		}
+		fmt.Fprintf(tw, "%v\t%s\t%s\n", set, summarizeSet(v.State.Set(set)), summarizeSet(host))
+	}
+	tw.Flush()
+	for _, w := range v.Warnings {
+		fmt.Fprintf(&b, "warning: %s\n", w)
+	}
+	return b.String()
+}
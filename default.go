@@ -0,0 +1,66 @@
+package capabilities
+
+import (
+	"sync"
+)
+
+var (
+	defaultOnce sync.Once
+	defaultMu   sync.Mutex
+	defaultCaps *Capabilities
+	defaultErr  error
+)
+
+// withDefault runs fn with the package-level Capabilities value, calling
+// Init the first time it is needed. Calls are serialized since queries
+// reuse the header and data buffers of the value.
+func withDefault(fn func(c *Capabilities) error) error {
+	defaultOnce.Do(func() {
+		defaultCaps, defaultErr = Init(WithProcRoot(defaultProcRoot))
+	})
+	if defaultErr != nil {
+		return defaultErr
+	}
+	defaultMu.Lock()
+	defer defaultMu.Unlock()
+	return fn(defaultCaps)
+}
+
+// Has returns true if the capability is in the effective set of the
+// calling thread.
+func Has(capability Cap) (bool, error) {
+	return HasIn(capability, Effective)
+}
+
+// HasIn returns true if the capability is in the capSet CapabilitySet of
+// the calling thread.
+func HasIn(capability Cap, capSet CapabilitySet) (bool, error) {
+	var set bool
+	err := withDefault(func(c *Capabilities) error {
+		var err error
+		set, err = c.isSetFor(0, int(capability), capSet)
+		return err
+	})
+	return set, err
+}
+
+// List returns the capabilities in the capSet CapabilitySet of the calling
+// thread.
+func List(capSet CapabilitySet) ([]Cap, error) {
+	caps, err := Get(capSet)
+	if err != nil {
+		return nil, err
+	}
+	return caps.Caps(), nil
+}
+
+// Get returns the capSet CapabilitySet of the calling thread as a CapSet.
+func Get(capSet CapabilitySet) (CapSet, error) {
+	var caps CapSet
+	err := withDefault(func(c *Capabilities) error {
+		var err error
+		caps, err = c.setFor(0, capSet)
+		return err
+	})
+	return caps, err
+}
@@ -0,0 +1,49 @@
+package capabilities
+
+import (
+	"fmt"
+	"os"
+	"strings"
+)
+
+// VerifyNoElevatedCaps checks that the process is fully deprivileged:
+// the effective, permitted, inheritable and ambient sets of every thread
+// are empty, the bounding set holds nothing beyond allowedBounding and
+// no_new_privs is set. Sandboxed services call it after initialization
+// and exit if it fails, rather than run with a sandbox that did not take
+// effect. The returned error lists every problem found.
+func VerifyNoElevatedCaps(allowedBounding ...Cap) error {
+	allowed := NewCapSet(allowedBounding...)
+	var problems []string
+	err := withDefault(func(c *Capabilities) error {
+		threads, err := c.Threads(os.Getpid())
+		if err != nil {
+			return err
+		}
+		for _, t := range threads {
+			for _, set := range []CapabilitySet{Effective, Permitted, Inheritable, Ambient} {
+				if held := t.State.Set(set); !held.IsEmpty() {
+					problems = append(problems, fmt.Sprintf("thread %d: %v set holds %s", t.Tid, set, FormatCapList(held)))
+				}
+			}
+			if extra := t.State.Bounding.Subtract(allowed); !extra.IsEmpty() {
+				problems = append(problems, fmt.Sprintf("thread %d: bounding set holds %s", t.Tid, summarizeSet(extra)))
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+	nnp, err := NoNewPrivs()
+	if err != nil {
+		return err
+	}
+	if !nnp {
+		problems = append(problems, "no_new_privs is not set")
+	}
+	if len(problems) > 0 {
+		return fmt.Errorf("process is not deprivileged: %s", strings.Join(problems, "; "))
+	}
+	return nil
+}
@@ -0,0 +1,146 @@
+package capabilities
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"strings"
+	"sync"
+)
+
+// Snapshot is the credential state of the calling thread captured by
+// Baseline or TakeSnapshot, to be compared with later states.
+type Snapshot struct {
+	SecurityContext
+}
+
+// Drift is the difference between a Snapshot and the current state.
+type Drift struct {
+	Baseline *SecurityContext
+	Current  *SecurityContext
+	// Gained and Lost are the capabilities added to and removed from
+	// each set.
+	Gained State
+	Lost   State
+	// Other describes changes to the other credentials, e.g.
+	// "euid 0 -> 1000" or "no_new_privs false -> true".
+	Other []string
+	// Err is set when Monitor could not read the current state. Current
+	// is then nil and no changes are reported.
+	Err error
+}
+
+// None returns true if nothing changed and the state could be read.
+func (d *Drift) None() bool {
+	return d.Gained == State{} && d.Lost == State{} && len(d.Other) == 0 && d.Err == nil
+}
+
+// String describes the changes, one per line, or returns "no drift".
+func (d *Drift) String() string {
+	if d.Err != nil {
+		return "check failed: " + d.Err.Error()
+	}
+	if d.None() {
+		return "no drift"
+	}
+	lines := describeSetChanges(&d.Gained, &d.Lost)
+	lines = append(lines, d.Other...)
+	return strings.Join(lines, "\n")
+}
+
+// describeSetChanges describes the capabilities gained and lost in each
+// set, e.g. "effective lost cap_net_raw".
+func describeSetChanges(gained, lost *State) []string {
+	var lines []string
+	for _, set := range []CapabilitySet{Effective, Permitted, Inheritable, Bounding, Ambient} {
+		if caps := gained.Set(set); !caps.IsEmpty() {
+			lines = append(lines, fmt.Sprintf("%v gained %s", set, FormatCapList(caps)))
+		}
+		if caps := lost.Set(set); !caps.IsEmpty() {
+			lines = append(lines, fmt.Sprintf("%v lost %s", set, FormatCapList(caps)))
+		}
+	}
+	return lines
+}
+
+// TakeSnapshot captures the credential state of the calling thread.
+func TakeSnapshot() (*Snapshot, error) {
+	var s *Snapshot
+	err := withDefault(func(c *Capabilities) error {
+		sc, err := c.SecurityContext(0)
+		if err != nil {
+			return err
+		}
+		s = &Snapshot{SecurityContext: *sc}
+		return nil
+	})
+	return s, err
+}
+
+// CheckDrift compares the credential state of the calling thread with
+// the snapshot.
+func (s *Snapshot) CheckDrift() (*Drift, error) {
+	now, err := TakeSnapshot()
+	if err != nil {
+		return nil, err
+	}
+	return s.diff(&now.SecurityContext), nil
+}
+
+// diff returns the drift from the snapshot to cur.
+func (s *Snapshot) diff(cur *SecurityContext) *Drift {
+	base := &s.SecurityContext
+	ch := Change{Old: base.State, New: cur.State}
+	d := &Drift{Baseline: base, Current: cur, Gained: ch.Gained(), Lost: ch.Lost()}
+	compare := func(name string, old, new interface{}) {
+		if !reflect.DeepEqual(old, new) {
+			d.Other = append(d.Other, fmt.Sprintf("%s %v -> %v", name, old, new))
+		}
+	}
+	compare("uid", base.UID, cur.UID)
+	compare("euid", base.EUID, cur.EUID)
+	compare("suid", base.SUID, cur.SUID)
+	compare("fsuid", base.FSUID, cur.FSUID)
+	compare("gid", base.GID, cur.GID)
+	compare("egid", base.EGID, cur.EGID)
+	compare("sgid", base.SGID, cur.SGID)
+	compare("fsgid", base.FSGID, cur.FSGID)
+	compare("groups", base.Groups, cur.Groups)
+	compare("no_new_privs", base.NoNewPrivs, cur.NoNewPrivs)
+	compare("seccomp", base.Seccomp, cur.Seccomp)
+	compare("securebits", base.Securebits, cur.Securebits)
+	return d
+}
+
+var (
+	baselineMu sync.Mutex
+	baseline   *Snapshot
+)
+
+// Baseline captures the credential state of the calling thread as the
+// baseline of the process, replacing any earlier one, for CheckDrift to
+// compare against. Long-running services call it once initialization,
+// including any capability drops, is complete.
+func Baseline() (*Snapshot, error) {
+	s, err := TakeSnapshot()
+	if err != nil {
+		return nil, err
+	}
+	baselineMu.Lock()
+	baseline = s
+	baselineMu.Unlock()
+	return s, nil
+}
+
+// CheckDrift compares the credential state of the calling thread with
+// the baseline captured by Baseline, detecting changes made since, e.g.
+// by a library or an attacker.
+func CheckDrift() (*Drift, error) {
+	baselineMu.Lock()
+	s := baseline
+	baselineMu.Unlock()
+	if s == nil {
+		return nil, errors.New("no baseline captured")
+	}
+	return s.CheckDrift()
+}
@@ -0,0 +1,66 @@
+package capabilities
+
+import (
+	"fmt"
+	"os"
+)
+
+// DropAllExcept reduces the effective, permitted and inheritable sets of
+// every thread to caps, raising caps in the effective set, and checks the
+// state of every thread afterwards. The ambient set follows, as the
+// kernel removes ambient capabilities that are no longer permitted and
+// inheritable. The capabilities must be permitted on the calling thread.
+//
+// Capabilities are set on all threads with syscall.AllThreadsSyscall,
+// which is not available in programs built with cgo; in that case an
+// error matching unix.ENOTSUP is returned before anything is changed.
+func DropAllExcept(caps ...Cap) error {
+	return dropAllExcept(NewCapSet(caps...), false)
+}
+
+// DropAllExceptBounding is like DropAllExcept but also reduces the
+// bounding set to caps, so the dropped capabilities cannot be regained
+// by executing programs. It requires CAP_SETPCAP.
+func DropAllExceptBounding(caps ...Cap) error {
+	return dropAllExcept(NewCapSet(caps...), true)
+}
+
+func dropAllExcept(keep CapSet, bounding bool) error {
+	return withDefault(func(c *Capabilities) error {
+		s, err := c.GetState(0)
+		if err != nil {
+			return err
+		}
+		if missing := keep.Subtract(s.Permitted); !missing.IsEmpty() {
+			return fmt.Errorf("capabilities %v not in permitted set", missing.Caps())
+		}
+		if bounding {
+			if err := c.allThreadsDropBounding(AllCaps().Subtract(keep)); err != nil {
+				return err
+			}
+		}
+		want := State{
+			Effective:   keep,
+			Permitted:   keep,
+			Inheritable: s.Inheritable.Intersect(keep),
+			Bounding:    s.Bounding,
+			Ambient:     s.Ambient.Intersect(keep),
+		}
+		if bounding {
+			want.Bounding = want.Bounding.Intersect(keep)
+		}
+		if err := allThreadsCapset(&want); err != nil {
+			return newError("capset", 0, err)
+		}
+		threads, err := c.Threads(os.Getpid())
+		if err != nil {
+			return err
+		}
+		for _, t := range threads {
+			if t.State != want {
+				return fmt.Errorf("capability state of thread %d after drop is %v, expected %v", t.Tid, &t.State, &want)
+			}
+		}
+		return nil
+	})
+}
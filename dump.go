@@ -0,0 +1,31 @@
+package capabilities
+
+import (
+	"fmt"
+	"io"
+	"text/tabwriter"
+)
+
+// Dump writes s as a table with one row per capability supported by the
+// kernel and one column per set, marking the sets that hold it:
+//
+//	CAPABILITY            E  P  I  B  A
+//	cap_chown             ✓  ✓  -  ✓  -
+//	cap_dac_override      -  ✓  -  ✓  -
+func (s *State) Dump(w io.Writer) error {
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(tw, "CAPABILITY\tE\tP\tI\tB\tA")
+	mark := func(set CapSet, c Cap) string {
+		if set.Contains(c) {
+			return "✓"
+		}
+		return "-"
+	}
+	for capability := 0; capability <= LastCap(); capability++ {
+		c := Cap(capability)
+		fmt.Fprintf(tw, "%s\t%s\t%s\t%s\t%s\t%s\n", c,
+			mark(s.Effective, c), mark(s.Permitted, c), mark(s.Inheritable, c),
+			mark(s.Bounding, c), mark(s.Ambient, c))
+	}
+	return tw.Flush()
+}
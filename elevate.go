@@ -0,0 +1,87 @@
+package capabilities
+
+import (
+	"fmt"
+	"runtime"
+)
+
+// ElevateOption configures WithCapability.
+type ElevateOption func(*elevateOptions)
+
+type elevateOptions struct {
+	allThreads bool
+}
+
+// WithAllThreads makes WithCapability raise the capability on every
+// thread of the process with syscall.AllThreadsSyscall instead of on a
+// locked thread, for callbacks that start goroutines. Every thread is
+// left with the sets of the calling thread. It is not available in
+// programs using cgo.
+func WithAllThreads() ElevateOption {
+	return func(o *elevateOptions) {
+		o.allThreads = true
+	}
+}
+
+// WithCapability raises capability in the effective set, runs fn and
+// lowers it again, even if fn panics, so a privileged operation holds
+// the capability no longer than needed. The capability must be
+// permitted. If it was already effective it stays effective.
+//
+// By default fn runs on a locked OS thread and only that thread holds
+// the capability; goroutines started by fn do not. WithAllThreads
+// raises it on every thread instead. If lowering the capability fails,
+// the locked thread is not returned to the scheduler and the error is
+// returned.
+func WithCapability(capability Cap, fn func() error, opts ...ElevateOption) (err error) {
+	var o elevateOptions
+	for _, opt := range opts {
+		opt(&o)
+	}
+	if !o.allThreads {
+		runtime.LockOSThread()
+	}
+	var old *State
+	err = withDefault(func(c *Capabilities) error {
+		var err error
+		if old, err = c.GetState(0); err != nil {
+			return err
+		}
+		if !old.Permitted.Contains(capability) {
+			return fmt.Errorf("capabilities %v not in permitted set", []Cap{capability})
+		}
+		raised := *old
+		raised.Effective = raised.Effective.Add(capability)
+		return setEffective(c, &raised, o.allThreads)
+	})
+	if err != nil {
+		if !o.allThreads {
+			runtime.UnlockOSThread()
+		}
+		return err
+	}
+	defer func() {
+		lowerErr := withDefault(func(c *Capabilities) error {
+			return setEffective(c, old, o.allThreads)
+		})
+		if lowerErr != nil {
+			if err == nil {
+				err = lowerErr
+			}
+			return
+		}
+		if !o.allThreads {
+			runtime.UnlockOSThread()
+		}
+	}()
+	return fn()
+}
+
+// setEffective sets the effective, permitted and inheritable sets to s on
+// the calling thread or on every thread.
+func setEffective(c *Capabilities, s *State, allThreads bool) error {
+	if allThreads {
+		return newError("capset", 0, allThreadsCapset(s))
+	}
+	return c.store(s)
+}
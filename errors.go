@@ -0,0 +1,194 @@
+package capabilities
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"golang.org/x/sys/unix"
+)
+
+var (
+	// ErrNoSuchProcess is matched by errors returned for a process that
+	// does not exist (ESRCH).
+	ErrNoSuchProcess = errors.New("no such process")
+	// ErrKernelTooOld is matched by errors returned when the running
+	// kernel lacks a capability or feature that was requested.
+	ErrKernelTooOld = errors.New("not supported by kernel")
+	// ErrNoFileCaps is matched by errors returned for a file that has no
+	// security.capability extended attribute.
+	ErrNoFileCaps = errors.New("file has no capabilities")
+)
+
+// ErrUnsupportedVersion is returned by Init when the kernel reports a
+// capability version this package does not know how to handle.
+type ErrUnsupportedVersion struct {
+	// Version is the raw version from the capability header,
+	// e.g. 0x20080522 for LINUX_CAPABILITY_VERSION_3.
+	Version uint32
+}
+
+func (e *ErrUnsupportedVersion) Error() string {
+	return fmt.Sprintf("unsupported Linux capability version 0x%08x", e.Version)
+}
+
+// ErrSetNotSupported is returned when a capability set is queried that the
+// capability version in use does not provide. LINUX_CAPABILITY_VERSION_1
+// has no bounding or ambient sets.
+type ErrSetNotSupported struct {
+	Set     CapabilitySet
+	Version int
+}
+
+func (e *ErrSetNotSupported) Error() string {
+	return fmt.Sprintf("capability set %v not supported for capability v%d", e.Set, e.Version)
+}
+
+// Error records a failed system call along with the pid it was made
+// for. The underlying errno is preserved, so errors.Is(err, unix.EPERM)
+// works. ESRCH additionally matches ErrNoSuchProcess, and ENOSYS matches
+// ErrKernelTooOld. EINVAL matches ErrKernelTooOld only for operations
+// whose arguments were validated beforehand, where it means the kernel
+// lacks the feature, e.g. PR_CAP_AMBIENT before Linux 4.3; elsewhere it
+// reports a bad argument.
+type Error struct {
+	// Op is the operation that failed, e.g. "capget".
+	Op string
+	// Pid is the target process. Zero means the calling thread.
+	Pid int
+	// Err is the underlying error, usually a unix.Errno.
+	Err error
+	// probe is set for operations where EINVAL means a missing kernel
+	// feature.
+	probe bool
+}
+
+func (e *Error) Error() string {
+	if e.Pid == 0 {
+		return e.Op + ": " + e.Err.Error()
+	}
+	return fmt.Sprintf("%s pid %d: %v", e.Op, e.Pid, e.Err)
+}
+
+// Unwrap returns the underlying error.
+func (e *Error) Unwrap() error {
+	return e.Err
+}
+
+// Is reports whether the error matches ErrNoSuchProcess or
+// ErrKernelTooOld.
+func (e *Error) Is(target error) bool {
+	switch target {
+	case ErrNoSuchProcess:
+		return errors.Is(e.Err, unix.ESRCH)
+	case ErrKernelTooOld:
+		return (e.probe && errors.Is(e.Err, unix.EINVAL)) || errors.Is(e.Err, unix.ENOSYS)
+	}
+	return false
+}
+
+func newError(op string, pid int, err error) error {
+	if err == nil {
+		return nil
+	}
+	return &Error{Op: op, Pid: pid, Err: err}
+}
+
+// newProbeError is like newError for operations that probe a kernel
+// feature, so that EINVAL matches ErrKernelTooOld.
+func newProbeError(op string, pid int, err error) error {
+	if err == nil {
+		return nil
+	}
+	return &Error{Op: op, Pid: pid, Err: err, probe: true}
+}
+
+// AmbientPromotionError is returned by PromoteToAmbient and records which
+// step failed for which capability.
+type AmbientPromotionError struct {
+	Cap Cap
+	// Step is the failing step: "check permitted", "raise inheritable"
+	// or "raise ambient".
+	Step string
+	// Err is the reason the step failed.
+	Err error
+}
+
+func (e *AmbientPromotionError) Error() string {
+	return fmt.Sprintf("promote %v to ambient: %s: %v", e.Cap, e.Step, e.Err)
+}
+
+// Unwrap returns the reason the step failed.
+func (e *AmbientPromotionError) Unwrap() error {
+	return e.Err
+}
+
+// CannotApplyError is returned by State.EnsureCanApply and lists every
+// reason the state cannot be applied.
+type CannotApplyError struct {
+	Reasons []string
+}
+
+func (e *CannotApplyError) Error() string {
+	return "cannot apply capability state: " + strings.Join(e.Reasons, "; ")
+}
+
+// MissingCapabilitiesError is returned by Require and RequirePermitted and
+// names every missing capability along with ways to grant them.
+type MissingCapabilitiesError struct {
+	// Missing are the required capabilities that are not held.
+	Missing CapSet
+	// Raisable are the capabilities of Missing that are permitted and
+	// only need to be raised in the effective set.
+	Raisable CapSet
+	// Program is the executable of the calling process, used in the
+	// setcap hint.
+	Program string
+}
+
+func (e *MissingCapabilitiesError) Error() string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "missing capabilities %s", FormatCapList(e.Missing))
+	if !e.Raisable.IsEmpty() {
+		fmt.Fprintf(&b, " (%s permitted but not effective)", FormatCapList(e.Raisable))
+	}
+	grant := e.Missing.Subtract(e.Raisable)
+	if grant.IsEmpty() {
+		return b.String()
+	}
+	var upper []string
+	for _, c := range grant.Caps() {
+		upper = append(upper, strings.ToUpper(c.String()))
+	}
+	fmt.Fprintf(&b, "; grant them with \"setcap %s=ep %s\" or the systemd directive \"AmbientCapabilities=%s\"",
+		FormatCapList(grant), e.Program, strings.Join(upper, " "))
+	return b.String()
+}
+
+// noFileCapsError reports a missing security.capability attribute. It
+// matches ErrNoFileCaps while keeping the errno, usually ENODATA, in the
+// chain.
+type noFileCapsError struct {
+	err error
+}
+
+func (e *noFileCapsError) Error() string {
+	return ErrNoFileCaps.Error()
+}
+
+func (e *noFileCapsError) Unwrap() error {
+	return e.err
+}
+
+func (e *noFileCapsError) Is(target error) bool {
+	return target == ErrNoFileCaps
+}
+
+// xattrError maps ENODATA from an xattr call to an error matching
+// ErrNoFileCaps.
+func xattrError(err error) error {
+	if errors.Is(err, unix.ENODATA) {
+		return &noFileCapsError{err: err}
+	}
+	return err
+}
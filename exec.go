@@ -0,0 +1,175 @@
+package capabilities
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+)
+
+// ExecCaller describes the process calling execve(2).
+type ExecCaller struct {
+	// State holds the capability sets of the caller.
+	State State
+	// UID, EUID, GID and EGID are the real and effective user and group
+	// IDs of the caller.
+	UID  int
+	EUID int
+	GID  int
+	EGID int
+	// Securebits are the securebits flags of the caller.
+	Securebits Securebits
+	// NoNewPrivs is the no_new_privs attribute of the caller.
+	NoNewPrivs bool
+}
+
+// ExecFile describes the program being executed.
+type ExecFile struct {
+	// Caps holds the file capabilities, or nil if the file has none.
+	Caps *FileCaps
+	// Mode holds the set-user-ID and set-group-ID bits of the file.
+	Mode fs.FileMode
+	// UID and GID are the owner of the file.
+	UID int
+	GID int
+}
+
+// ExecResult is the credential state after execve(2).
+type ExecResult struct {
+	State State
+	EUID  int
+	EGID  int
+}
+
+// ErrCapabilityDumb is returned by ExecTransition when a file with the
+// effective bit set would not receive all of its permitted capabilities,
+// typically because they are missing from the bounding set.
+// The kernel refuses such an execve(2) with EPERM, since a
+// capability-unaware program could not cope with missing privileges.
+var ErrCapabilityDumb = errors.New("execve would fail: file effective bit set but file permitted capabilities not granted")
+
+// ExecTransition computes the capability sets a program runs with after
+// caller executes file, following the transformation in capabilities(7):
+//
+//	P'(ambient)     = (file is privileged) ? 0 : P(ambient)
+//	P'(permitted)   = (P(inheritable) & F(inheritable)) |
+//	                  (F(permitted) & P(bounding)) | P'(ambient)
+//	P'(effective)   = F(effective) ? P'(permitted) : P'(ambient)
+//	P'(inheritable) = P(inheritable)
+//	P'(bounding)    = P(bounding)
+//
+// including the special treatment of root (unless SECBIT_NOROOT is set),
+// set-user-ID and set-group-ID files, and no_new_privs. ptrace and LSM
+// restrictions are not modelled.
+func ExecTransition(caller *ExecCaller, file *ExecFile) (*ExecResult, error) {
+	p := caller.State
+	euid, egid := caller.EUID, caller.EGID
+	// With no_new_privs the set-user-ID and set-group-ID bits are
+	// ignored.
+	if !caller.NoNewPrivs {
+		if file.Mode&fs.ModeSetuid != 0 {
+			euid = file.UID
+		}
+		if file.Mode&fs.ModeSetgid != 0 {
+			egid = file.GID
+		}
+	}
+	isSetid := euid != caller.UID || egid != caller.GID
+
+	hasFcap := file.Caps != nil
+	var fP, fI CapSet
+	effective := false
+	if hasFcap {
+		fP = file.Caps.Permitted
+		fI = file.Caps.Inheritable
+		effective = file.Caps.Effective
+	}
+	permitted := fP.Intersect(p.Bounding).Union(fI.Intersect(p.Inheritable))
+	if effective && !permitted.ContainsAll(fP) {
+		return nil, ErrCapabilityDumb
+	}
+
+	if caller.Securebits&SecbitNoroot == 0 {
+		// A set-user-ID root file with file capabilities run by a
+		// non-root user gets only its file capabilities.
+		suidRoot := caller.UID != 0 && euid == 0
+		if !(hasFcap && suidRoot) {
+			if euid == 0 || caller.UID == 0 {
+				permitted = p.Bounding.Union(p.Inheritable)
+			}
+			if euid == 0 {
+				effective = true
+			}
+		}
+	}
+
+	if caller.NoNewPrivs && !permitted.Subtract(p.Permitted).IsEmpty() {
+		permitted = permitted.Intersect(p.Permitted)
+	}
+
+	ambient := p.Ambient
+	if hasFcap || isSetid {
+		ambient = 0
+	}
+	permitted = permitted.Union(ambient)
+
+	newState := State{
+		Effective:   ambient,
+		Permitted:   permitted,
+		Inheritable: p.Inheritable,
+		Bounding:    p.Bounding,
+		Ambient:     ambient,
+	}
+	if effective {
+		newState.Effective = permitted
+	}
+	return &ExecResult{State: newState, EUID: euid, EGID: egid}, nil
+}
+
+// RequiredFileCaps returns the minimal file capabilities a program needs
+// so that it runs with want in its effective and permitted sets when
+// executed by caller. The result can be applied with SetFileCaps or
+// rendered for setcap(8) with FileCaps.Text. A nil result means no file
+// capabilities are needed, for example because caller is root or already
+// passes want on through its ambient set.
+//
+// An error is returned if want cannot be granted through file
+// capabilities, e.g. because it is not within the bounding set of caller
+// or caller has no_new_privs set.
+func RequiredFileCaps(caller *ExecCaller, want CapSet) (*FileCaps, error) {
+	if r, err := ExecTransition(caller, &ExecFile{UID: caller.EUID, GID: caller.EGID}); err == nil && r.State.Effective.ContainsAll(want) {
+		return nil, nil
+	}
+	if missing := want.Subtract(caller.State.Bounding); !missing.IsEmpty() {
+		return nil, fmt.Errorf("capabilities %v not in bounding set", missing.Caps())
+	}
+	fc := &FileCaps{Permitted: want, Effective: true}
+	r, err := ExecTransition(caller, &ExecFile{Caps: fc, UID: caller.EUID, GID: caller.EGID})
+	if err != nil {
+		return nil, err
+	}
+	if missing := want.Subtract(r.State.Effective); !missing.IsEmpty() {
+		return nil, fmt.Errorf("capabilities %v cannot be gained through execve", missing.Caps())
+	}
+	return fc, nil
+}
+
+// RequiredAmbient returns the ambient set caller must configure so that
+// a program without file capabilities, set-user-ID or set-group-ID bits
+// runs with want in its effective and permitted sets. It is the
+// alternative to RequiredFileCaps when the program file should be left
+// unmodified.
+//
+// Ambient capabilities must be both permitted and inheritable, and
+// cannot be raised while SECBIT_NO_CAP_AMBIENT_RAISE is set.
+func RequiredAmbient(caller *ExecCaller, want CapSet) (CapSet, error) {
+	if caller.Securebits&SecbitNoCapAmbientRaise != 0 {
+		if missing := want.Subtract(caller.State.Ambient); !missing.IsEmpty() {
+			return 0, fmt.Errorf("ambient capabilities %v cannot be raised with SECBIT_NO_CAP_AMBIENT_RAISE set", missing.Caps())
+		}
+	}
+	allowed := caller.State.Permitted.Intersect(caller.State.Inheritable)
+	if missing := want.Subtract(allowed); !missing.IsEmpty() {
+		return 0, fmt.Errorf("ambient capabilities %v not in permitted and inheritable sets", missing.Caps())
+	}
+	return want, nil
+}
@@ -0,0 +1,151 @@
+package capabilities
+
+import (
+	"io/fs"
+	"testing"
+)
+
+func TestExecTransition(t *testing.T) {
+	all := AllCaps()
+	netBind := NewCapSet(CapNetBindService)
+	user := ExecCaller{
+		State: State{Bounding: all},
+		UID:   1000, EUID: 1000, GID: 1000, EGID: 1000,
+	}
+	root := ExecCaller{
+		State: State{Effective: all, Permitted: all, Bounding: all},
+	}
+	with := func(c ExecCaller, fn func(*ExecCaller)) *ExecCaller {
+		fn(&c)
+		return &c
+	}
+	tests := []struct {
+		name    string
+		caller  *ExecCaller
+		file    *ExecFile
+		want    State
+		euid    int
+		wantErr error
+	}{
+		{
+			name:   "plain file",
+			caller: &user,
+			file:   &ExecFile{},
+			want:   State{Bounding: all},
+			euid:   1000,
+		},
+		{
+			name:   "file capabilities",
+			caller: &user,
+			file:   &ExecFile{Caps: &FileCaps{Permitted: netBind, Effective: true}},
+			want:   State{Effective: netBind, Permitted: netBind, Bounding: all},
+			euid:   1000,
+		},
+		{
+			name:   "file capabilities without effective bit",
+			caller: &user,
+			file:   &ExecFile{Caps: &FileCaps{Permitted: netBind}},
+			want:   State{Permitted: netBind, Bounding: all},
+			euid:   1000,
+		},
+		{
+			name: "file capabilities outside bounding set",
+			caller: with(user, func(c *ExecCaller) {
+				c.State.Bounding = all.Remove(CapNetBindService)
+			}),
+			file:    &ExecFile{Caps: &FileCaps{Permitted: netBind, Effective: true}},
+			wantErr: ErrCapabilityDumb,
+		},
+		{
+			name: "inheritable",
+			caller: with(user, func(c *ExecCaller) {
+				c.State.Inheritable = netBind
+			}),
+			file: &ExecFile{Caps: &FileCaps{Inheritable: netBind, Effective: true}},
+			want: State{Effective: netBind, Permitted: netBind, Inheritable: netBind, Bounding: all},
+			euid: 1000,
+		},
+		{
+			name: "ambient",
+			caller: with(user, func(c *ExecCaller) {
+				c.State = State{Permitted: netBind, Inheritable: netBind, Bounding: all, Ambient: netBind}
+			}),
+			file: &ExecFile{},
+			want: State{Effective: netBind, Permitted: netBind, Inheritable: netBind, Bounding: all, Ambient: netBind},
+			euid: 1000,
+		},
+		{
+			name: "ambient cleared by file capabilities",
+			caller: with(user, func(c *ExecCaller) {
+				c.State = State{Permitted: netBind, Inheritable: netBind, Bounding: all, Ambient: netBind}
+			}),
+			file: &ExecFile{Caps: &FileCaps{Permitted: NewCapSet(CapNetRaw)}},
+			want: State{Permitted: NewCapSet(CapNetRaw), Inheritable: netBind, Bounding: all},
+			euid: 1000,
+		},
+		{
+			name:   "root",
+			caller: &root,
+			file:   &ExecFile{},
+			want:   State{Effective: all, Permitted: all, Bounding: all},
+		},
+		{
+			name: "root with noroot",
+			caller: with(root, func(c *ExecCaller) {
+				c.Securebits = SecbitNoroot
+			}),
+			file: &ExecFile{},
+			want: State{Bounding: all},
+		},
+		{
+			name:   "set-user-ID root",
+			caller: &user,
+			file:   &ExecFile{Mode: fs.ModeSetuid},
+			want:   State{Effective: all, Permitted: all, Bounding: all},
+		},
+		{
+			name: "set-user-ID root with no_new_privs",
+			caller: with(user, func(c *ExecCaller) {
+				c.NoNewPrivs = true
+			}),
+			file: &ExecFile{Mode: fs.ModeSetuid},
+			want: State{Bounding: all},
+			euid: 1000,
+		},
+		{
+			name:   "set-user-ID root with file capabilities",
+			caller: &user,
+			file:   &ExecFile{Mode: fs.ModeSetuid, Caps: &FileCaps{Permitted: netBind}},
+			want:   State{Permitted: netBind, Bounding: all},
+		},
+		{
+			name: "file capabilities with no_new_privs",
+			caller: with(user, func(c *ExecCaller) {
+				c.NoNewPrivs = true
+			}),
+			file: &ExecFile{Caps: &FileCaps{Permitted: netBind}},
+			want: State{Bounding: all},
+			euid: 1000,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ExecTransition(tt.caller, tt.file)
+			if tt.wantErr != nil {
+				if err != tt.wantErr {
+					t.Fatalf("ExecTransition error = %v, want %v", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got.State != tt.want {
+				t.Errorf("ExecTransition state = %v, want %v", &got.State, &tt.want)
+			}
+			if got.EUID != tt.euid {
+				t.Errorf("ExecTransition euid = %d, want %d", got.EUID, tt.euid)
+			}
+		})
+	}
+}
@@ -0,0 +1,54 @@
+package capabilities
+
+// capDescriptions summarizes what each capability grants. The text is
+// condensed from capabilities(7).
+var capDescriptions = map[Cap]string{
+	CapChown:             "Make arbitrary changes to file UIDs and GIDs.",
+	CapDacOverride:       "Bypass file read, write, and execute permission checks.",
+	CapDacReadSearch:     "Bypass file read permission checks and directory read and execute permission checks; invoke open_by_handle_at(2).",
+	CapFowner:            "Bypass permission checks on operations that normally require the file system UID of the process to match the UID of the file, such as chmod(2) and utime(2); set inode flags and extended attributes on arbitrary files.",
+	CapFsetid:            "Keep set-user-ID and set-group-ID bits when a file is modified; set the set-group-ID bit for a file whose GID does not match the caller's groups.",
+	CapKill:              "Bypass permission checks for sending signals.",
+	CapSetgid:            "Make arbitrary manipulations of process GIDs and the supplementary GID list; forge GIDs when passing socket credentials; write a group ID mapping in a user namespace.",
+	CapSetuid:            "Make arbitrary manipulations of process UIDs; forge UIDs when passing socket credentials; write a user ID mapping in a user namespace.",
+	CapSetpcap:           "Add any capability from the bounding set to the inheritable set; drop capabilities from the bounding set; make changes to the securebits flags.",
+	CapLinuxImmutable:    "Set the FS_APPEND_FL and FS_IMMUTABLE_FL inode flags.",
+	CapNetBindService:    "Bind a socket to Internet domain privileged ports (port numbers less than 1024).",
+	CapNetBroadcast:      "Make socket broadcasts and listen to multicasts (unused).",
+	CapNetAdmin:          "Perform network administration: configure interfaces, routing tables and firewalls, set promiscuous mode, enable multicasting, set privileged socket options.",
+	CapNetRaw:            "Use RAW and PACKET sockets; bind to any address for transparent proxying.",
+	CapIpcLock:           "Lock memory (mlock(2), mlockall(2), mmap(2), shmctl(2)) and allocate memory using huge pages.",
+	CapIpcOwner:          "Bypass permission checks for operations on System V IPC objects.",
+	CapSysModule:         "Load and unload kernel modules; drop capabilities from the system-wide bounding set.",
+	CapSysRawio:          "Perform I/O port operations (iopl(2), ioperm(2)); access /proc/kcore and /dev/mem; use FIBMAP.",
+	CapSysChroot:         "Use chroot(2); change mount namespaces using setns(2).",
+	CapSysPtrace:         "Trace arbitrary processes using ptrace(2); apply get_robust_list(2) and cross-memory attach to arbitrary processes; inspect processes using kcmp(2).",
+	CapSysPacct:          "Use acct(2).",
+	CapSysAdmin:          "Perform a wide range of system administration operations, including mount(2), sethostname(2), quotactl(2), swapon(2), setns(2), clone(2) with namespace flags, and many privileged ioctl(2) and keyctl(2) operations. Often considered equivalent to root.",
+	CapSysBoot:           "Use reboot(2) and kexec_load(2).",
+	CapSysNice:           "Raise process nice values and change the nice value of arbitrary processes; set real-time scheduling policies and CPU affinity for arbitrary processes; set I/O scheduling class and priority.",
+	CapSysResource:       "Use reserved file system space; override resource limits, disk quota limits and IPC message queue limits; set hard RLIMIT_NPROC and other resource limits.",
+	CapSysTime:           "Set the system clock and the real-time (hardware) clock.",
+	CapSysTtyConfig:      "Use vhangup(2) and privileged ioctl(2) operations on virtual terminals.",
+	CapMknod:             "Create special files using mknod(2).",
+	CapLease:             "Establish leases on arbitrary files.",
+	CapAuditWrite:        "Write records to the kernel auditing log.",
+	CapAuditControl:      "Enable and disable kernel auditing; change auditing filter rules; retrieve auditing status and filtering rules.",
+	CapSetfcap:           "Set arbitrary capabilities on a file; map user ID 0 in a new user namespace.",
+	CapMacOverride:       "Override Mandatory Access Control (MAC), as implemented by some Linux Security Modules such as Smack.",
+	CapMacAdmin:          "Allow MAC configuration or state changes, as implemented by some Linux Security Modules such as Smack.",
+	CapSyslog:            "Perform privileged syslog(2) operations; view kernel addresses exposed via /proc when kptr_restrict is 1.",
+	CapWakeAlarm:         "Trigger something that will wake up the system (CLOCK_REALTIME_ALARM and CLOCK_BOOTTIME_ALARM timers).",
+	CapBlockSuspend:      "Employ features that can block system suspend (EPOLLWAKEUP, /proc/sys/wake_lock).",
+	CapAuditRead:         "Read the audit log via a multicast netlink socket.",
+	CapPerfmon:           "Employ performance monitoring mechanisms, including perf_event_open(2) and various BPF operations with performance implications.",
+	CapBpf:               "Employ privileged BPF operations, such as creating all map types and loading programs, in combination with CAP_PERFMON or CAP_NET_ADMIN for tracing and networking programs.",
+	CapCheckpointRestore: "Perform checkpoint and restore operations: update /proc/sys/kernel/ns_last_pid, use set_tid with clone3(2), read the contents of /proc/<pid>/map_files of other processes.",
+}
+
+// Explain returns a short description of what capability c grants, based
+// on capabilities(7). It returns an empty string for capabilities unknown
+// to this package.
+func Explain(c Cap) string {
+	return capDescriptions[c]
+}
@@ -0,0 +1,317 @@
+package capabilities
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"os"
+
+	"github.com/opcoder0/capabilities/internal"
+	"golang.org/x/sys/unix"
+)
+
+// XattrName is the name of the extended attribute holding file
+// capabilities.
+const XattrName = internal.XattrNameCaps
+
+// FileCaps holds the capabilities attached to a file through the
+// security.capability extended attribute. On execve(2) the permitted and
+// inheritable sets are combined with the caller's capabilities as
+// described in capabilities(7).
+type FileCaps struct {
+	// Permitted capabilities are added to the permitted set of the new
+	// program (subject to the bounding set).
+	Permitted CapSet
+	// Inheritable capabilities are ANDed with the caller's inheritable
+	// set and added to the permitted set of the new program.
+	Inheritable CapSet
+	// Effective is the VFS_CAP_FLAGS_EFFECTIVE bit, the "e" in setcap
+	// syntax. Unlike the other sets it is a single flag for the whole
+	// file: when set, every capability the new program gains in its
+	// permitted set is also raised in its effective set, so
+	// capability-unaware programs can use them immediately. When clear,
+	// the capabilities are only permitted and the program has to raise
+	// them itself.
+	Effective bool
+	// Version is the VFS_CAP_REVISION of the attribute, 1, 2 or 3.
+	Version int
+	// RootID is the user ID, in the initial user namespace, of root in
+	// the user namespace that wrote a revision 3 attribute. The
+	// capabilities only apply when the file is executed in a user
+	// namespace whose root maps to RootID.
+	RootID uint32
+}
+
+// EffectiveCaps returns the file capabilities that are raised in the
+// effective set on execve(2): the permitted and inheritable sets if the
+// effective bit is set, otherwise none.
+func (fc *FileCaps) EffectiveCaps() CapSet {
+	if !fc.Effective {
+		return 0
+	}
+	return fc.Permitted.Union(fc.Inheritable)
+}
+
+// PermittedOnly returns the file capabilities that become permitted on
+// execve(2) without being raised in the effective set.
+func (fc *FileCaps) PermittedOnly() CapSet {
+	if fc.Effective {
+		return 0
+	}
+	return fc.Permitted.Union(fc.Inheritable)
+}
+
+// GetFileCaps reads and decodes the capabilities of the file at path, the
+// equivalent of getcap(8). If the file has no capabilities the returned
+// error matches ErrNoFileCaps.
+func GetFileCaps(path string) (*FileCaps, error) {
+	buf := make([]byte, internal.XattrCapsSz3)
+	n, err := unix.Getxattr(path, internal.XattrNameCaps, buf)
+	if err != nil {
+		err = xattrError(err)
+		return nil, &os.PathError{Op: "getxattr", Path: path, Err: err}
+	}
+	fc, err := DecodeFileCaps(buf[:n])
+	if err != nil {
+		return nil, &os.PathError{Op: "getxattr", Path: path, Err: err}
+	}
+	return fc, nil
+}
+
+// DecodeFileCaps decodes the raw value of a security.capability extended
+// attribute: a magic word holding the revision and flags, the permitted
+// and inheritable data words, and for revision 3 the root ID. It lets
+// tools such as archivers and image builders inspect attributes stored
+// in tar headers or disk images.
+func DecodeFileCaps(b []byte) (*FileCaps, error) {
+	if len(b) < 4 {
+		return nil, fmt.Errorf("invalid file capability length %d", len(b))
+	}
+	// The attribute is little-endian regardless of the host byte order.
+	var data internal.VfsNsCapData
+	raw := make([]byte, internal.XattrCapsSz3)
+	copy(raw, b)
+	if err := binary.Read(bytes.NewReader(raw), binary.LittleEndian, &data); err != nil {
+		return nil, err
+	}
+	var fc FileCaps
+	words := 0
+	switch data.MagicEtc & internal.VfsCapRevisionMask {
+	case internal.VfsCapRevision1:
+		if len(b) != internal.XattrCapsSz1 {
+			return nil, fmt.Errorf("invalid file capability length %d for revision 1", len(b))
+		}
+		fc.Version = 1
+		words = internal.VfsCapU32_1
+	case internal.VfsCapRevision2:
+		if len(b) != internal.XattrCapsSz2 {
+			return nil, fmt.Errorf("invalid file capability length %d for revision 2", len(b))
+		}
+		fc.Version = 2
+		words = internal.VfsCapU32_2
+	case internal.VfsCapRevision3:
+		if len(b) != internal.XattrCapsSz3 {
+			return nil, fmt.Errorf("invalid file capability length %d for revision 3", len(b))
+		}
+		fc.Version = 3
+		fc.RootID = data.RootID
+		words = internal.VfsCapU32_3
+	default:
+		return nil, fmt.Errorf("unsupported file capability revision 0x%08x", data.MagicEtc&internal.VfsCapRevisionMask)
+	}
+	fc.Effective = data.MagicEtc&internal.VfsCapFlagsEffective != 0
+	for i := 0; i < words; i++ {
+		fc.Permitted |= CapSet(data.Data[i].Permitted) << uint(32*i)
+		fc.Inheritable |= CapSet(data.Data[i].Inheritable) << uint(32*i)
+	}
+	return &fc, nil
+}
+
+// SetFileCaps encodes fc and writes it to the security.capability
+// extended attribute of the file at path, the equivalent of setcap(8).
+// Writing file capabilities requires CAP_SETFCAP. A zero fc.Version
+// writes a revision 2 attribute. Revision 3 attributes carry fc.RootID.
+// The kernel may convert between revisions 2 and 3 depending on the user
+// namespace of the writer.
+func SetFileCaps(path string, fc *FileCaps) error {
+	b, err := EncodeFileCaps(fc)
+	if err != nil {
+		return &os.PathError{Op: "setxattr", Path: path, Err: err}
+	}
+	if err := unix.Setxattr(path, internal.XattrNameCaps, b, 0); err != nil {
+		return &os.PathError{Op: "setxattr", Path: path, Err: err}
+	}
+	return nil
+}
+
+// EncodeFileCaps returns fc encoded as the raw value of a
+// security.capability extended attribute, suitable for storing in a tar
+// header (as SCHILY.xattr.security.capability) or a disk image. A zero
+// fc.Version encodes revision 2. The attribute is encoded little-endian,
+// as the kernel stores it, on every architecture.
+func EncodeFileCaps(fc *FileCaps) ([]byte, error) {
+	var data internal.VfsNsCapData
+	var size, words int
+	switch fc.Version {
+	case 1:
+		if (fc.Permitted|fc.Inheritable)>>32 != 0 {
+			return nil, errors.New("capabilities above 31 not supported for file capability revision 1")
+		}
+		data.MagicEtc = internal.VfsCapRevision1
+		size, words = internal.XattrCapsSz1, internal.VfsCapU32_1
+	case 0, 2:
+		data.MagicEtc = internal.VfsCapRevision2
+		size, words = internal.XattrCapsSz2, internal.VfsCapU32_2
+	case 3:
+		data.MagicEtc = internal.VfsCapRevision3
+		data.RootID = fc.RootID
+		size, words = internal.XattrCapsSz3, internal.VfsCapU32_3
+	default:
+		return nil, fmt.Errorf("unsupported file capability revision %d", fc.Version)
+	}
+	if fc.Version != 3 && fc.RootID != 0 {
+		return nil, errors.New("root ID requires file capability revision 3")
+	}
+	if fc.Effective {
+		data.MagicEtc |= internal.VfsCapFlagsEffective
+	}
+	for i := 0; i < words; i++ {
+		data.Data[i].Permitted = uint32(fc.Permitted >> uint(32*i))
+		data.Data[i].Inheritable = uint32(fc.Inheritable >> uint(32*i))
+	}
+	var buf bytes.Buffer
+	if err := binary.Write(&buf, binary.LittleEndian, &data); err != nil {
+		return nil, err
+	}
+	return buf.Bytes()[:size], nil
+}
+
+// RemoveFileCaps removes the security.capability extended attribute from
+// the file at path, the equivalent of setcap -r. If the file has no
+// capabilities the returned error matches ErrNoFileCaps.
+func RemoveFileCaps(path string) error {
+	if err := unix.Removexattr(path, internal.XattrNameCaps); err != nil {
+		err = xattrError(err)
+		return &os.PathError{Op: "removexattr", Path: path, Err: err}
+	}
+	return nil
+}
+
+// GetFileCapsFd is like GetFileCaps but reads the capabilities of an open
+// file with fgetxattr(2), so the file that was opened and checked is the
+// one inspected even if its path is replaced.
+func GetFileCapsFd(f *os.File) (*FileCaps, error) {
+	buf := make([]byte, internal.XattrCapsSz3)
+	var n int
+	err := controlFd(f, func(fd int) error {
+		var err error
+		n, err = unix.Fgetxattr(fd, internal.XattrNameCaps, buf)
+		return err
+	})
+	if err != nil {
+		err = xattrError(err)
+		return nil, &os.PathError{Op: "fgetxattr", Path: f.Name(), Err: err}
+	}
+	fc, err := DecodeFileCaps(buf[:n])
+	if err != nil {
+		return nil, &os.PathError{Op: "fgetxattr", Path: f.Name(), Err: err}
+	}
+	return fc, nil
+}
+
+// SetFileCapsFd is like SetFileCaps but writes the capabilities of an
+// open file with fsetxattr(2).
+func SetFileCapsFd(f *os.File, fc *FileCaps) error {
+	b, err := EncodeFileCaps(fc)
+	if err == nil {
+		err = controlFd(f, func(fd int) error {
+			return unix.Fsetxattr(fd, internal.XattrNameCaps, b, 0)
+		})
+	}
+	if err != nil {
+		return &os.PathError{Op: "fsetxattr", Path: f.Name(), Err: err}
+	}
+	return nil
+}
+
+// controlFd calls fn with the descriptor of f without changing its
+// blocking mode, as f.Fd would.
+func controlFd(f *os.File, fn func(fd int) error) error {
+	rc, err := f.SyscallConn()
+	if err != nil {
+		return err
+	}
+	var fnErr error
+	err = rc.Control(func(fd uintptr) {
+		fnErr = fn(int(fd))
+	})
+	if err != nil {
+		return err
+	}
+	return fnErr
+}
+
+// ParseFileCapsText parses file capabilities in the clause syntax
+// accepted by setcap(8), e.g. "cap_net_bind_service=+ep" or
+// "cap_net_raw,cap_net_admin=ep cap_kill+i". As with setcap, the
+// effective flag must be given for all of the permitted and inheritable
+// capabilities or for none of them.
+func ParseFileCapsText(text string) (*FileCaps, error) {
+	s, err := ParseText(text)
+	if err != nil {
+		return nil, err
+	}
+	fc := &FileCaps{
+		Permitted:   s.Permitted,
+		Inheritable: s.Inheritable,
+		Effective:   !s.Effective.IsEmpty(),
+	}
+	if fc.Effective && s.Effective != s.Permitted.Union(s.Inheritable) {
+		return nil, fmt.Errorf("file capabilities %q: effective flag must be set for all or none of the permitted and inheritable capabilities", text)
+	}
+	return fc, nil
+}
+
+// Text returns fc in the clause syntax printed by getcap(8) and accepted
+// by setcap(8), e.g. "cap_net_bind_service=ep".
+func (fc *FileCaps) Text() string {
+	s := State{
+		Effective:   fc.EffectiveCaps(),
+		Permitted:   fc.Permitted,
+		Inheritable: fc.Inheritable,
+	}
+	return s.Text()
+}
+
+// String returns fc in setcap clause syntax.
+func (fc *FileCaps) String() string {
+	return fc.Text()
+}
+
+// CopyFileCaps makes the capabilities of the file at dst match those of
+// src. The raw attribute is copied, preserving the revision, the
+// effective flag and the root ID. If src has no capabilities any
+// capabilities on dst are removed. Copying is useful after staging or
+// moving a binary with tools that drop extended attributes.
+func CopyFileCaps(src, dst string) error {
+	buf := make([]byte, internal.XattrCapsSz3)
+	n, err := unix.Getxattr(src, internal.XattrNameCaps, buf)
+	if err != nil {
+		if !errors.Is(err, unix.ENODATA) {
+			return &os.PathError{Op: "getxattr", Path: src, Err: err}
+		}
+		err = RemoveFileCaps(dst)
+		if errors.Is(err, ErrNoFileCaps) {
+			return nil
+		}
+		return err
+	}
+	if _, err := DecodeFileCaps(buf[:n]); err != nil {
+		return &os.PathError{Op: "getxattr", Path: src, Err: err}
+	}
+	if err := unix.Setxattr(dst, internal.XattrNameCaps, buf[:n], 0); err != nil {
+		return &os.PathError{Op: "setxattr", Path: dst, Err: err}
+	}
+	return nil
+}
@@ -0,0 +1,117 @@
+package capabilities
+
+import (
+	"bytes"
+	"testing"
+)
+
+func TestFileCapsEncoding(t *testing.T) {
+	tests := []struct {
+		name    string
+		fc      FileCaps
+		encoded []byte
+	}{
+		{
+			name: "revision 1",
+			fc:   FileCaps{Permitted: NewCapSet(CapNetRaw), Inheritable: NewCapSet(CapChown), Version: 1},
+			encoded: []byte{
+				0x00, 0x00, 0x00, 0x01,
+				0x00, 0x20, 0x00, 0x00,
+				0x01, 0x00, 0x00, 0x00,
+			},
+		},
+		{
+			name: "revision 2",
+			fc:   FileCaps{Permitted: NewCapSet(CapNetBindService), Effective: true, Version: 2},
+			encoded: []byte{
+				0x01, 0x00, 0x00, 0x02,
+				0x00, 0x04, 0x00, 0x00,
+				0x00, 0x00, 0x00, 0x00,
+				0x00, 0x00, 0x00, 0x00,
+				0x00, 0x00, 0x00, 0x00,
+			},
+		},
+		{
+			name: "revision 2 high capabilities",
+			fc:   FileCaps{Permitted: NewCapSet(CapSyslog, CapBpf), Inheritable: NewCapSet(CapSetfcap), Version: 2},
+			encoded: []byte{
+				0x00, 0x00, 0x00, 0x02,
+				0x00, 0x00, 0x00, 0x00,
+				0x00, 0x00, 0x00, 0x80,
+				0x84, 0x00, 0x00, 0x00,
+				0x00, 0x00, 0x00, 0x00,
+			},
+		},
+		{
+			name: "revision 3",
+			fc:   FileCaps{Permitted: NewCapSet(CapNetRaw), Effective: true, Version: 3, RootID: 100000},
+			encoded: []byte{
+				0x01, 0x00, 0x00, 0x03,
+				0x00, 0x20, 0x00, 0x00,
+				0x00, 0x00, 0x00, 0x00,
+				0x00, 0x00, 0x00, 0x00,
+				0x00, 0x00, 0x00, 0x00,
+				0xa0, 0x86, 0x01, 0x00,
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			b, err := EncodeFileCaps(&tt.fc)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !bytes.Equal(b, tt.encoded) {
+				t.Errorf("EncodeFileCaps = % x, want % x", b, tt.encoded)
+			}
+			fc, err := DecodeFileCaps(tt.encoded)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if *fc != tt.fc {
+				t.Errorf("DecodeFileCaps = %+v, want %+v", *fc, tt.fc)
+			}
+		})
+	}
+}
+
+func TestEncodeFileCapsErrors(t *testing.T) {
+	tests := []struct {
+		name string
+		fc   FileCaps
+	}{
+		{"revision 1 high capability", FileCaps{Permitted: NewCapSet(CapSyslog), Version: 1}},
+		{"root ID without revision 3", FileCaps{RootID: 1000, Version: 2}},
+		{"unknown revision", FileCaps{Version: 4}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if b, err := EncodeFileCaps(&tt.fc); err == nil {
+				t.Errorf("EncodeFileCaps = % x, want error", b)
+			}
+		})
+	}
+}
+
+func TestDecodeFileCapsErrors(t *testing.T) {
+	tests := []struct {
+		name    string
+		encoded []byte
+	}{
+		{"short", []byte{0x00, 0x00}},
+		{"revision 2 length", []byte{0x00, 0x00, 0x00, 0x02, 0x00, 0x04, 0x00, 0x00}},
+		{"revision 3 length", []byte{
+			0x00, 0x00, 0x00, 0x03,
+			0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00,
+			0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00,
+		}},
+		{"unknown revision", []byte{0x00, 0x00, 0x00, 0x04, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if fc, err := DecodeFileCaps(tt.encoded); err == nil {
+				t.Errorf("DecodeFileCaps = %+v, want error", fc)
+			}
+		})
+	}
+}
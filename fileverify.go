@@ -0,0 +1,128 @@
+package capabilities
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// FileCapsMismatch reports how the capabilities of a file differ from the
+// expected ones. The attribute revision is not compared, since the kernel
+// converts between revisions 2 and 3 on its own.
+type FileCapsMismatch struct {
+	Path string
+	// Expected and Actual are the expected and found capabilities. Either
+	// is nil when the file should have, or has, no capabilities.
+	Expected *FileCaps
+	Actual   *FileCaps
+	// MissingPermitted and MissingInheritable hold the expected
+	// capabilities the file lacks; ExtraPermitted and ExtraInheritable
+	// hold the capabilities it carries beyond those expected.
+	MissingPermitted   CapSet
+	ExtraPermitted     CapSet
+	MissingInheritable CapSet
+	ExtraInheritable   CapSet
+	// Effective is true if the effective flag differs.
+	Effective bool
+	// RootID is true if the root ID of a namespaced attribute differs.
+	RootID bool
+}
+
+// OK returns true if the file carries exactly the expected capabilities.
+func (m *FileCapsMismatch) OK() bool {
+	return m.MissingPermitted.IsEmpty() && m.ExtraPermitted.IsEmpty() &&
+		m.MissingInheritable.IsEmpty() && m.ExtraInheritable.IsEmpty() &&
+		!m.Effective && !m.RootID
+}
+
+// String summarizes the differences, e.g.
+// "/usr/bin/ping: permitted missing cap_net_raw; effective flag not set".
+func (m *FileCapsMismatch) String() string {
+	if m.OK() {
+		return m.Path + ": ok"
+	}
+	var diffs []string
+	add := func(format string, caps CapSet) {
+		if !caps.IsEmpty() {
+			diffs = append(diffs, fmt.Sprintf(format, FormatCapList(caps)))
+		}
+	}
+	add("permitted missing %s", m.MissingPermitted)
+	add("permitted extra %s", m.ExtraPermitted)
+	add("inheritable missing %s", m.MissingInheritable)
+	add("inheritable extra %s", m.ExtraInheritable)
+	if m.Effective {
+		if m.Expected != nil && m.Expected.Effective {
+			diffs = append(diffs, "effective flag not set")
+		} else {
+			diffs = append(diffs, "effective flag set")
+		}
+	}
+	if m.RootID {
+		diffs = append(diffs, "root ID differs")
+	}
+	return m.Path + ": " + strings.Join(diffs, "; ")
+}
+
+// VerifyFileCaps compares the capabilities of the file at path with
+// expected, for packaging and health checks that assert a binary still
+// carries the capabilities it was installed with. A nil expected asserts
+// the file has no capabilities. The returned error is only set when the
+// file cannot be inspected; differences are reported in the result.
+func VerifyFileCaps(path string, expected *FileCaps) (*FileCapsMismatch, error) {
+	actual, err := GetFileCaps(path)
+	if err != nil {
+		if !errors.Is(err, ErrNoFileCaps) {
+			return nil, err
+		}
+		actual = nil
+	}
+	return compareFileCaps(path, expected, actual), nil
+}
+
+// compareFileCaps computes the differences between expected and actual,
+// treating nil as a file without capabilities.
+func compareFileCaps(path string, expected, actual *FileCaps) *FileCapsMismatch {
+	var want, got FileCaps
+	if expected != nil {
+		want = *expected
+	}
+	if actual != nil {
+		got = *actual
+	}
+	return &FileCapsMismatch{
+		Path:               path,
+		Expected:           expected,
+		Actual:             actual,
+		MissingPermitted:   want.Permitted.Subtract(got.Permitted),
+		ExtraPermitted:     got.Permitted.Subtract(want.Permitted),
+		MissingInheritable: want.Inheritable.Subtract(got.Inheritable),
+		ExtraInheritable:   got.Inheritable.Subtract(want.Inheritable),
+		Effective:          want.Effective != got.Effective,
+		RootID:             want.RootID != got.RootID,
+	}
+}
+
+// EnsureFileCaps makes the capabilities of the file at path match
+// desired, writing the attribute only if they differ. A nil desired
+// removes any capabilities. The result reports whether the file was
+// changed, giving configuration management tools idempotent semantics.
+func EnsureFileCaps(path string, desired *FileCaps) (bool, error) {
+	m, err := VerifyFileCaps(path, desired)
+	if err != nil {
+		return false, err
+	}
+	if m.OK() {
+		return false, nil
+	}
+	if desired == nil {
+		if err := RemoveFileCaps(path); err != nil && !errors.Is(err, ErrNoFileCaps) {
+			return false, err
+		}
+		return true, nil
+	}
+	if err := SetFileCaps(path, desired); err != nil {
+		return false, err
+	}
+	return true, nil
+}
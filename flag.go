@@ -0,0 +1,39 @@
+package capabilities
+
+// CapList is a flag.Value holding a comma separated list of capability
+// names such as "cap_net_raw,cap_chown". Names are accepted in any form
+// understood by ParseCap. Repeating the flag adds to the list. CapList
+// also implements the Type method expected by github.com/spf13/pflag.
+//
+//	var caps capabilities.CapList
+//	flag.Var(&caps, "caps", "capabilities to keep")
+type CapList CapSet
+
+// String returns the capabilities as a comma separated list of names.
+func (l *CapList) String() string {
+	if l == nil {
+		return ""
+	}
+	return FormatCapList(CapSet(*l))
+}
+
+// Set parses a comma separated list of capability names and adds them to
+// the list.
+func (l *CapList) Set(value string) error {
+	caps, err := ParseCapList(value)
+	if err != nil {
+		return err
+	}
+	*l = CapList(CapSet(*l).Union(caps))
+	return nil
+}
+
+// Type returns the value type name shown in pflag usage output.
+func (l *CapList) Type() string {
+	return "caps"
+}
+
+// CapSet returns the capabilities in the list.
+func (l *CapList) CapSet() CapSet {
+	return CapSet(*l)
+}
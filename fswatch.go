@@ -0,0 +1,101 @@
+package capabilities
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"unsafe"
+
+	"golang.org/x/sys/unix"
+)
+
+// fileCapsWatchMask selects the inotify events that can change the
+// capabilities found in a directory. Setting or removing an extended
+// attribute is reported as IN_ATTRIB.
+const fileCapsWatchMask = unix.IN_ATTRIB | unix.IN_CREATE | unix.IN_CLOSE_WRITE |
+	unix.IN_MOVED_TO | unix.IN_MOVED_FROM | unix.IN_DELETE
+
+// WatchFileCaps watches the files in dirs (not their subdirectories) with
+// inotify(7) and sends a FileCapsChange whenever a file gains, loses or
+// changes its capabilities, e.g. /usr/bin and /usr/local/bin to catch a
+// setcap as it happens. Files moved into or created in a directory with
+// capabilities are reported as gaining them. The capabilities are read
+// when the event is processed, so changes in quick succession may be
+// reported as one. The channel is closed when ctx is done.
+func WatchFileCaps(ctx context.Context, dirs ...string) (<-chan FileCapsChange, error) {
+	fd, err := unix.InotifyInit1(unix.IN_CLOEXEC | unix.IN_NONBLOCK)
+	if err != nil {
+		return nil, newError("inotify_init1", 0, err)
+	}
+	watches := make(map[int]string)
+	known := make(map[string]*FileCaps)
+	for _, dir := range dirs {
+		wd, err := unix.InotifyAddWatch(fd, dir, fileCapsWatchMask)
+		if err != nil {
+			unix.Close(fd)
+			return nil, &os.PathError{Op: "inotify_add_watch", Path: dir, Err: err}
+		}
+		watches[wd] = dir
+		entries, err := os.ReadDir(dir)
+		if err != nil {
+			unix.Close(fd)
+			return nil, err
+		}
+		for _, e := range entries {
+			path := filepath.Join(dir, e.Name())
+			if fc, err := GetFileCaps(path); err == nil {
+				known[path] = fc
+			}
+		}
+	}
+	f := os.NewFile(uintptr(fd), "inotify")
+	changes := make(chan FileCapsChange)
+	go func() {
+		<-ctx.Done()
+		f.Close()
+	}()
+	go func() {
+		defer close(changes)
+		buf := make([]byte, 64*(unix.SizeofInotifyEvent+unix.NAME_MAX+1))
+		for {
+			n, err := f.Read(buf)
+			if err != nil {
+				return
+			}
+			for off := 0; off+unix.SizeofInotifyEvent <= n; {
+				ev := (*unix.InotifyEvent)(unsafe.Pointer(&buf[off]))
+				name := buf[off+unix.SizeofInotifyEvent : off+unix.SizeofInotifyEvent+int(ev.Len)]
+				off += unix.SizeofInotifyEvent + int(ev.Len)
+				dir, ok := watches[int(ev.Wd)]
+				if !ok || ev.Len == 0 {
+					continue
+				}
+				path := filepath.Join(dir, unix.ByteSliceToString(name))
+				var fc *FileCaps
+				if ev.Mask&(unix.IN_DELETE|unix.IN_MOVED_FROM) == 0 {
+					var err error
+					fc, err = GetFileCaps(path)
+					if err != nil && !errors.Is(err, ErrNoFileCaps) {
+						continue
+					}
+				}
+				old := known[path]
+				if compareFileCaps(path, old, fc).OK() {
+					continue
+				}
+				if fc == nil {
+					delete(known, path)
+				} else {
+					known[path] = fc
+				}
+				select {
+				case changes <- FileCapsChange{Path: path, Old: old, New: fc}:
+				case <-ctx.Done():
+					return
+				}
+			}
+		}
+	}()
+	return changes, nil
+}
@@ -0,0 +1,26 @@
+package capabilities
+
+// Predefined capability groups.
+const (
+	// NetworkCaps holds the capabilities for configuring and using the
+	// network stack.
+	NetworkCaps = CapSet(1<<CapNetAdmin | 1<<CapNetRaw | 1<<CapNetBindService)
+	// FileAccessCaps holds the capabilities that bypass file ownership and
+	// permission checks.
+	FileAccessCaps = CapSet(1<<CapChown | 1<<CapDacOverride | 1<<CapDacReadSearch | 1<<CapFowner | 1<<CapFsetid)
+	// DockerDefaultCaps holds the capabilities granted to containers by
+	// Docker's default configuration.
+	DockerDefaultCaps = CapSet(1<<CapChown | 1<<CapDacOverride | 1<<CapFsetid | 1<<CapFowner |
+		1<<CapMknod | 1<<CapNetRaw | 1<<CapSetgid | 1<<CapSetuid | 1<<CapSetfcap |
+		1<<CapSetpcap | 1<<CapNetBindService | 1<<CapSysChroot | 1<<CapKill | 1<<CapAuditWrite)
+)
+
+// AllCaps returns the set of every capability supported by the running
+// kernel.
+func AllCaps() CapSet {
+	var s CapSet
+	for capability := 0; capability <= LastCap(); capability++ {
+		s = s.Add(Cap(capability))
+	}
+	return s
+}
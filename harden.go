@@ -0,0 +1,115 @@
+package capabilities
+
+import (
+	"fmt"
+
+	"golang.org/x/sys/unix"
+)
+
+// allSecurebitLocks are the lock companions of every securebits flag.
+const allSecurebitLocks = SecbitNorootLocked | SecbitNoSetuidFixupLocked |
+	SecbitKeepCapsLocked | SecbitNoCapAmbientRaiseLocked
+
+// defaultHardenSecurebits are the securebits set by Harden unless
+// WithSecurebits is given, and by the preset profiles: root gets no
+// capabilities on exec or on changes of user ID, and no ambient
+// capabilities can be raised.
+const defaultHardenSecurebits = SecbitNoroot | SecbitNoSetuidFixup | SecbitNoCapAmbientRaise
+
+// HardenOption configures Harden.
+type HardenOption func(*hardenOptions)
+
+type hardenOptions struct {
+	allowed    CapSet
+	securebits Securebits
+}
+
+// WithAllowed sets the capabilities Harden keeps. By default every
+// capability is dropped.
+func WithAllowed(caps ...Cap) HardenOption {
+	return func(o *hardenOptions) {
+		o.allowed = o.allowed.Union(NewCapSet(caps...))
+	}
+}
+
+// WithSecurebits sets the securebits flags Harden enables before locking
+// all of them. The default is SecbitNoroot, SecbitNoSetuidFixup and
+// SecbitNoCapAmbientRaise.
+func WithSecurebits(bits Securebits) HardenOption {
+	return func(o *hardenOptions) {
+		o.securebits = bits
+	}
+}
+
+// Harden locks down every thread of the process so that it can never
+// regain privileges:
+//
+//  1. the ambient set is cleared
+//  2. every capability not allowed is dropped from the bounding set
+//  3. the securebits are set and every securebits lock is set
+//  4. no_new_privs is set
+//  5. the effective, permitted and inheritable sets are reduced to the
+//     allowed capabilities
+//
+// Steps 2 and 3 require CAP_SETPCAP in the effective set, which is
+// dropped in step 5 unless it is allowed, and the allowed capabilities
+// must be permitted; both are checked before anything is changed.
+//
+// Every step is applied to all threads with syscall.AllThreadsSyscall,
+// which is not available in programs built with cgo; in that case an
+// error matching unix.ENOTSUP is returned before anything is changed.
+func Harden(opts ...HardenOption) error {
+	o := newHardenOptions(opts...)
+	return withDefault(func(c *Capabilities) error {
+		s, err := c.GetState(0)
+		if err != nil {
+			return err
+		}
+		hardened, err := o.plan(s)
+		if err != nil {
+			return err
+		}
+		if err := allThreadsPrctl(unix.PR_CAP_AMBIENT, unix.PR_CAP_AMBIENT_CLEAR_ALL); err != nil {
+			return newProbeError("clear ambient capabilities", 0, err)
+		}
+		if err := c.allThreadsDropBounding(AllCaps().Subtract(o.allowed)); err != nil {
+			return err
+		}
+		if err := SetSecurebitsAllThreads(o.securebits | allSecurebitLocks); err != nil {
+			return err
+		}
+		if err := SetNoNewPrivsAllThreads(); err != nil {
+			return err
+		}
+		if err := allThreadsCapset(hardened); err != nil {
+			return newError("capset", 0, err)
+		}
+		return nil
+	})
+}
+
+// newHardenOptions applies opts to the defaults.
+func newHardenOptions(opts ...HardenOption) hardenOptions {
+	o := hardenOptions{securebits: defaultHardenSecurebits}
+	for _, opt := range opts {
+		opt(&o)
+	}
+	return o
+}
+
+// plan returns the effective, permitted and inheritable sets Harden
+// stores for a thread in state s. CAP_SETPCAP must be effective and the
+// allowed capabilities must be permitted.
+func (o *hardenOptions) plan(s *State) (*State, error) {
+	if !s.Effective.Contains(CapSetpcap) {
+		return nil, fmt.Errorf("harden: CAP_SETPCAP not in effective set: %w", unix.EPERM)
+	}
+	if missing := o.allowed.Subtract(s.Permitted); !missing.IsEmpty() {
+		return nil, fmt.Errorf("capabilities %v not in permitted set", missing.Caps())
+	}
+	return &State{
+		Effective:   s.Effective.Intersect(o.allowed),
+		Permitted:   o.allowed,
+		Inheritable: s.Inheritable.Intersect(o.allowed),
+	}, nil
+}
@@ -0,0 +1,120 @@
+package capabilities
+
+import (
+	"testing"
+)
+
+func TestNewHardenOptions(t *testing.T) {
+	tests := []struct {
+		name       string
+		opts       []HardenOption
+		allowed    CapSet
+		securebits Securebits
+	}{
+		{
+			name:       "defaults",
+			securebits: defaultHardenSecurebits,
+		},
+		{
+			name:       "allowed",
+			opts:       []HardenOption{WithAllowed(CapNetBindService, CapNetRaw)},
+			allowed:    NewCapSet(CapNetBindService, CapNetRaw),
+			securebits: defaultHardenSecurebits,
+		},
+		{
+			name:       "allowed accumulates",
+			opts:       []HardenOption{WithAllowed(CapNetBindService), WithAllowed(CapNetRaw)},
+			allowed:    NewCapSet(CapNetBindService, CapNetRaw),
+			securebits: defaultHardenSecurebits,
+		},
+		{
+			name:       "securebits",
+			opts:       []HardenOption{WithSecurebits(SecbitNoroot)},
+			securebits: SecbitNoroot,
+		},
+		{
+			name:       "securebits last wins",
+			opts:       []HardenOption{WithSecurebits(SecbitNoroot), WithSecurebits(SecbitKeepCaps)},
+			securebits: SecbitKeepCaps,
+		},
+		{
+			name:    "no securebits",
+			opts:    []HardenOption{WithSecurebits(0), WithAllowed(CapKill)},
+			allowed: NewCapSet(CapKill),
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			o := newHardenOptions(tt.opts...)
+			if o.allowed != tt.allowed {
+				t.Errorf("allowed = %v, want %v", o.allowed, tt.allowed)
+			}
+			if o.securebits != tt.securebits {
+				t.Errorf("securebits = %v, want %v", o.securebits, tt.securebits)
+			}
+		})
+	}
+}
+
+func TestHardenPlan(t *testing.T) {
+	current := &State{
+		Effective:   NewCapSet(CapNetBindService, CapSetpcap),
+		Permitted:   NewCapSet(CapNetBindService, CapNetRaw, CapSetpcap),
+		Inheritable: NewCapSet(CapNetRaw, CapSysAdmin),
+		Bounding:    AllCaps(),
+		Ambient:     NewCapSet(CapNetRaw),
+	}
+	tests := []struct {
+		name    string
+		opts    []HardenOption
+		state   *State
+		want    *State
+		wantErr bool
+	}{
+		{
+			name: "drop everything",
+			want: &State{},
+		},
+		{
+			name: "keep allowed",
+			opts: []HardenOption{WithAllowed(CapNetBindService, CapNetRaw)},
+			want: &State{
+				Effective:   NewCapSet(CapNetBindService),
+				Permitted:   NewCapSet(CapNetBindService, CapNetRaw),
+				Inheritable: NewCapSet(CapNetRaw),
+			},
+		},
+		{
+			name:    "allowed not permitted",
+			opts:    []HardenOption{WithAllowed(CapSysAdmin)},
+			wantErr: true,
+		},
+		{
+			name:    "setpcap not effective",
+			state:   &State{Permitted: current.Permitted, Bounding: AllCaps()},
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := current
+			if tt.state != nil {
+				s = tt.state
+			}
+			o := newHardenOptions(tt.opts...)
+			got, err := o.plan(s)
+			if tt.wantErr {
+				if err == nil {
+					t.Fatalf("plan = %v, want error", got)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if *got != *tt.want {
+				t.Errorf("plan = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
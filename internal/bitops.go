@@ -44,11 +44,45 @@ type CapabilityV1 struct {
 // See also
 // https://git.kernel.org/pub/scm/linux/kernel/git/morgan/libcap.git/tree/libcap/libcap.h#n115
 // indicating datap[0] and datap[1] for 64 bit capabilities.
+//
+// The sets are stored as slices of 32-bit words so that the structure
+// grows with the number of capabilities supported by the kernel. Use
+// NewCapabilityV3 to allocate the words.
 type CapabilityV3 struct {
 	Header  unix.CapUserHeader
-	Datap   [2]unix.CapUserData
-	Bounds  [2]uint32
-	Ambient [2]uint32
+	Datap   []unix.CapUserData
+	Bounds  []uint32
+	Ambient []uint32
+}
+
+// minWords is the number of 32-bit words the kernel reads and writes
+// for LINUX_CAPABILITY_VERSION_2 and LINUX_CAPABILITY_VERSION_3.
+const minWords = 2
+
+// Words returns the number of 32-bit words needed to hold capabilities
+// 0 through lastCap, and never less than the kernel ABI requires.
+func Words(lastCap int) int {
+	n := lastCap/32 + 1
+	if n < minWords {
+		n = minWords
+	}
+	return n
+}
+
+// NewCapabilityV3 returns a CapabilityV3 sized for capabilities 0 through
+// lastCap.
+func NewCapabilityV3(lastCap int) CapabilityV3 {
+	n := Words(lastCap)
+	return CapabilityV3{
+		Datap:   make([]unix.CapUserData, n),
+		Bounds:  make([]uint32, n),
+		Ambient: make([]uint32, n),
+	}
+}
+
+// wordBit returns the word index and bit mask of capability.
+func wordBit(capability int) (int, uint32) {
+	return capability / 32, 1 << uint(capability%32)
 }
 
 func (v1 *CapabilityV1) IsEffectiveSet(capability int) bool {
@@ -64,51 +98,26 @@ func (v1 *CapabilityV1) IsInheritableSet(capability int) bool {
 }
 
 func (v3 *CapabilityV3) IsEffectiveSet(capability int) bool {
-	var i uint
-	bitIndex := capability
-	if bitIndex > 31 {
-		i = 1
-		bitIndex %= 32
-	}
-	return (1<<uint(bitIndex))&v3.Datap[i].Effective != 0
+	i, bit := wordBit(capability)
+	return i < len(v3.Datap) && bit&v3.Datap[i].Effective != 0
 }
 
 func (v3 *CapabilityV3) IsPermittedSet(capability int) bool {
-	var i uint
-	bitIndex := capability
-	if bitIndex > 31 {
-		i = 1
-		bitIndex %= 32
-	}
-	return (1<<uint(bitIndex))&v3.Datap[i].Permitted != 0
+	i, bit := wordBit(capability)
+	return i < len(v3.Datap) && bit&v3.Datap[i].Permitted != 0
 }
 
 func (v3 *CapabilityV3) IsInheritableSet(capability int) bool {
-	var i uint
-	bitIndex := capability
-	if bitIndex > 31 {
-		i = 1
-		bitIndex %= 32
-	}
-	return (1<<uint(bitIndex))&v3.Datap[i].Inheritable != 0
+	i, bit := wordBit(capability)
+	return i < len(v3.Datap) && bit&v3.Datap[i].Inheritable != 0
 }
 
 func (v3 *CapabilityV3) IsBoundingSet(capability int) bool {
-	var i uint
-	bitIndex := capability
-	if bitIndex > 31 {
-		i = 1
-		bitIndex %= 32
-	}
-	return (1<<uint(bitIndex))&v3.Bounds[i] != 0
+	i, bit := wordBit(capability)
+	return i < len(v3.Bounds) && bit&v3.Bounds[i] != 0
 }
 
 func (v3 *CapabilityV3) IsAmbientSet(capability int) bool {
-	var i uint
-	bitIndex := capability
-	if bitIndex > 31 {
-		i = 1
-		bitIndex %= 32
-	}
-	return (1<<uint(bitIndex))&v3.Ambient[i] != 0
+	i, bit := wordBit(capability)
+	return i < len(v3.Ambient) && bit&v3.Ambient[i] != 0
 }
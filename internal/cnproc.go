@@ -0,0 +1,59 @@
+package internal
+
+// Definitions of the kernel proc connector protocol from
+// linux/connector.h and linux/cn_proc.h. Messages are in host byte order.
+
+const (
+	CnIdxProc = 0x1
+	CnValProc = 0x1
+
+	ProcCnMcastListen = 1
+	ProcCnMcastIgnore = 2
+
+	ProcEventFork = 0x00000001
+	ProcEventExec = 0x00000002
+	ProcEventExit = 0x80000000
+)
+
+// CnMsg is struct cn_msg without its trailing data.
+type CnMsg struct {
+	Idx   uint32
+	Val   uint32
+	Seq   uint32
+	Ack   uint32
+	Len   uint16
+	Flags uint16
+}
+
+// ProcEventHeader is the fixed part of struct proc_event preceding the
+// event_data union.
+type ProcEventHeader struct {
+	What        uint32
+	CPU         uint32
+	TimestampNs uint64
+}
+
+// ForkProcEvent is struct fork_proc_event.
+type ForkProcEvent struct {
+	ParentPid  int32
+	ParentTgid int32
+	ChildPid   int32
+	ChildTgid  int32
+}
+
+// ExecProcEvent is struct exec_proc_event.
+type ExecProcEvent struct {
+	ProcessPid  int32
+	ProcessTgid int32
+}
+
+// ExitProcEvent is struct exit_proc_event. ParentPid and ParentTgid were
+// added in Linux 4.18.
+type ExitProcEvent struct {
+	ProcessPid  int32
+	ProcessTgid int32
+	ExitCode    uint32
+	ExitSignal  uint32
+	ParentPid   int32
+	ParentTgid  int32
+}
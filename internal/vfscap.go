@@ -0,0 +1,38 @@
+package internal
+
+// Definitions of the security.capability extended attribute layout from
+// linux/capability.h.
+
+// XattrNameCaps is the name of the extended attribute holding file
+// capabilities.
+const XattrNameCaps = "security.capability"
+
+const (
+	VfsCapRevisionMask   = 0xff000000
+	VfsCapFlagsEffective = 0x000001
+
+	VfsCapRevision1 = 0x01000000
+	VfsCapU32_1     = 1
+	XattrCapsSz1    = 4 * (1 + 2*VfsCapU32_1)
+
+	VfsCapRevision2 = 0x02000000
+	VfsCapU32_2     = 2
+	XattrCapsSz2    = 4 * (1 + 2*VfsCapU32_2)
+
+	VfsCapRevision3 = 0x03000000
+	VfsCapU32_3     = 2
+	XattrCapsSz3    = 4 * (2 + 2*VfsCapU32_3)
+)
+
+// VfsNsCapData is struct vfs_ns_cap_data, the layout of revision 3
+// attributes. It extends struct vfs_cap_data, the layout of revision 1
+// and 2 attributes, with the root user ID of the user namespace the
+// attribute was written in. Shorter revisions are read into its prefix.
+type VfsNsCapData struct {
+	MagicEtc uint32
+	Data     [VfsCapU32_3]struct {
+		Permitted   uint32
+		Inheritable uint32
+	}
+	RootID uint32
+}
@@ -0,0 +1,91 @@
+package capabilities
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Irreversible describes the parts of the credential state of the
+// calling thread that can no longer be changed at runtime, by the thread
+// or by any program it executes.
+type Irreversible struct {
+	// LockedSecurebits are the securebits flags whose lock is set. Their
+	// values are fixed at those in Securebits.
+	LockedSecurebits Securebits
+	Securebits       Securebits
+	// NoNewPrivs is true if no_new_privs is set, so execve(2) can no
+	// longer grant capabilities.
+	NoNewPrivs bool
+	// DroppedBounding are the capabilities removed from the bounding
+	// set. They cannot be gained again, even by executing programs.
+	DroppedBounding CapSet
+	// Setpcap is true if CAP_SETPCAP is permitted. Without it the
+	// bounding set and the unlocked securebits cannot be changed
+	// either.
+	Setpcap bool
+}
+
+// GetIrreversible reports which parts of the credential state of the
+// calling thread are irreversible: locked securebits, no_new_privs and
+// capabilities dropped from the bounding set.
+func GetIrreversible() (*Irreversible, error) {
+	var irr Irreversible
+	err := withDefault(func(c *Capabilities) error {
+		s, err := c.GetState(0)
+		if err != nil {
+			return err
+		}
+		irr.DroppedBounding = AllCaps().Subtract(s.Bounding)
+		irr.Setpcap = s.Permitted.Contains(CapSetpcap)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	if irr.Securebits, err = GetSecurebits(); err != nil {
+		return nil, err
+	}
+	irr.LockedSecurebits = irr.Securebits >> 1 & securebitFlags
+	if irr.NoNewPrivs, err = NoNewPrivs(); err != nil {
+		return nil, err
+	}
+	return &irr, nil
+}
+
+// String describes the irreversible configuration, one aspect per line:
+//
+//	locked securebits: noroot=on,keep_caps=off
+//	no_new_privs: set
+//	dropped from bounding set: cap_sys_admin,cap_sys_module
+//	CAP_SETPCAP: not permitted
+func (irr *Irreversible) String() string {
+	var b strings.Builder
+	var locked []string
+	for i, name := range securebitNames {
+		flag := Securebits(1) << uint(i)
+		if irr.LockedSecurebits&flag == 0 {
+			continue
+		}
+		value := "off"
+		if irr.Securebits&flag != 0 {
+			value = "on"
+		}
+		locked = append(locked, name+"="+value)
+	}
+	if len(locked) == 0 {
+		locked = append(locked, "none")
+	}
+	fmt.Fprintf(&b, "locked securebits: %s\n", strings.Join(locked, ","))
+	nnp := "unset"
+	if irr.NoNewPrivs {
+		nnp = "set"
+	}
+	fmt.Fprintf(&b, "no_new_privs: %s\n", nnp)
+	fmt.Fprintf(&b, "dropped from bounding set: %s\n", summarizeSet(irr.DroppedBounding))
+	setpcap := "permitted"
+	if !irr.Setpcap {
+		setpcap = "not permitted"
+	}
+	fmt.Fprintf(&b, "CAP_SETPCAP: %s\n", setpcap)
+	return b.String()
+}
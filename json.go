@@ -0,0 +1,67 @@
+package capabilities
+
+import (
+	"encoding/json"
+)
+
+// stateJSON is the JSON form of State with each set as a list of names.
+type stateJSON struct {
+	Effective   []string `json:"effective"`
+	Permitted   []string `json:"permitted"`
+	Inheritable []string `json:"inheritable"`
+	Bounding    []string `json:"bounding"`
+	Ambient     []string `json:"ambient"`
+}
+
+// MarshalJSON encodes s as an object with one list of capability names
+// per set, e.g. {"effective":["cap_chown"],"permitted":["cap_chown"],...}.
+func (s State) MarshalJSON() ([]byte, error) {
+	return json.Marshal(stateJSON{
+		Effective:   setNames(s.Effective),
+		Permitted:   setNames(s.Permitted),
+		Inheritable: setNames(s.Inheritable),
+		Bounding:    setNames(s.Bounding),
+		Ambient:     setNames(s.Ambient),
+	})
+}
+
+// UnmarshalJSON decodes the form produced by MarshalJSON. Capability names
+// are accepted in any form understood by ParseCap. Missing sets are empty.
+func (s *State) UnmarshalJSON(data []byte) error {
+	var v stateJSON
+	if err := json.Unmarshal(data, &v); err != nil {
+		return err
+	}
+	var st State
+	var err error
+	if st.Effective, err = parseNames(v.Effective); err != nil {
+		return err
+	}
+	if st.Permitted, err = parseNames(v.Permitted); err != nil {
+		return err
+	}
+	if st.Inheritable, err = parseNames(v.Inheritable); err != nil {
+		return err
+	}
+	if st.Bounding, err = parseNames(v.Bounding); err != nil {
+		return err
+	}
+	if st.Ambient, err = parseNames(v.Ambient); err != nil {
+		return err
+	}
+	*s = st
+	return nil
+}
+
+// parseNames returns the set of capabilities named in names.
+func parseNames(names []string) (CapSet, error) {
+	var caps CapSet
+	for _, name := range names {
+		c, err := ParseCap(name)
+		if err != nil {
+			return 0, err
+		}
+		caps = caps.Add(c)
+	}
+	return caps, nil
+}
@@ -0,0 +1,92 @@
+package capabilities
+
+import (
+	"encoding/json"
+	"testing"
+)
+
+func TestJSONRoundTrip(t *testing.T) {
+	tests := []struct {
+		name  string
+		state State
+		json  string
+	}{
+		{
+			name: "empty",
+			json: `{"effective":[],"permitted":[],"inheritable":[],"bounding":[],"ambient":[]}`,
+		},
+		{
+			name: "sets",
+			state: State{
+				Effective:   NewCapSet(CapChown),
+				Permitted:   NewCapSet(CapChown, CapKill),
+				Inheritable: NewCapSet(CapKill),
+				Bounding:    NewCapSet(CapChown, CapKill, CapSysAdmin),
+				Ambient:     NewCapSet(CapKill),
+			},
+			json: `{"effective":["cap_chown"],"permitted":["cap_chown","cap_kill"],"inheritable":["cap_kill"],` +
+				`"bounding":["cap_chown","cap_kill","cap_sys_admin"],"ambient":["cap_kill"]}`,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			b, err := json.Marshal(tt.state)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if string(b) != tt.json {
+				t.Errorf("Marshal = %s, want %s", b, tt.json)
+			}
+			var s State
+			if err := json.Unmarshal(b, &s); err != nil {
+				t.Fatal(err)
+			}
+			if s != tt.state {
+				t.Errorf("Unmarshal = %v, want %v", &s, &tt.state)
+			}
+		})
+	}
+}
+
+func TestUnmarshalJSON(t *testing.T) {
+	tests := []struct {
+		name    string
+		json    string
+		want    State
+		wantErr bool
+	}{
+		{
+			name: "missing sets",
+			json: `{"effective":["CAP_NET_RAW"]}`,
+			want: State{Effective: NewCapSet(CapNetRaw)},
+		},
+		{
+			name:    "unknown capability",
+			json:    `{"permitted":["cap_bogus"]}`,
+			wantErr: true,
+		},
+		{
+			name:    "malformed",
+			json:    `{"permitted":`,
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var s State
+			err := json.Unmarshal([]byte(tt.json), &s)
+			if tt.wantErr {
+				if err == nil {
+					t.Fatalf("Unmarshal = %v, want error", &s)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if s != tt.want {
+				t.Errorf("Unmarshal = %v, want %v", &s, &tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,163 @@
+package capabilities
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"sync"
+
+	"golang.org/x/sys/unix"
+)
+
+// KernelVersion is a Linux kernel release number such as 5.8 or 2.6.25.
+type KernelVersion struct {
+	Major int
+	Minor int
+	Patch int
+}
+
+// String returns the version in dotted form. The patch level is omitted
+// when it is zero.
+func (v KernelVersion) String() string {
+	if v.Patch == 0 {
+		return fmt.Sprintf("%d.%d", v.Major, v.Minor)
+	}
+	return fmt.Sprintf("%d.%d.%d", v.Major, v.Minor, v.Patch)
+}
+
+// Less returns true if v is an earlier release than w.
+func (v KernelVersion) Less(w KernelVersion) bool {
+	if v.Major != w.Major {
+		return v.Major < w.Major
+	}
+	if v.Minor != w.Minor {
+		return v.Minor < w.Minor
+	}
+	return v.Patch < w.Patch
+}
+
+// capIntroduced records the kernel release each capability first appeared
+// in. See capabilities(7).
+var capIntroduced = map[Cap]KernelVersion{
+	CapChown:             {2, 2, 0},
+	CapDacOverride:       {2, 2, 0},
+	CapDacReadSearch:     {2, 2, 0},
+	CapFowner:            {2, 2, 0},
+	CapFsetid:            {2, 2, 0},
+	CapKill:              {2, 2, 0},
+	CapSetgid:            {2, 2, 0},
+	CapSetuid:            {2, 2, 0},
+	CapSetpcap:           {2, 2, 0},
+	CapLinuxImmutable:    {2, 2, 0},
+	CapNetBindService:    {2, 2, 0},
+	CapNetBroadcast:      {2, 2, 0},
+	CapNetAdmin:          {2, 2, 0},
+	CapNetRaw:            {2, 2, 0},
+	CapIpcLock:           {2, 2, 0},
+	CapIpcOwner:          {2, 2, 0},
+	CapSysModule:         {2, 2, 0},
+	CapSysRawio:          {2, 2, 0},
+	CapSysChroot:         {2, 2, 0},
+	CapSysPtrace:         {2, 2, 0},
+	CapSysPacct:          {2, 2, 0},
+	CapSysAdmin:          {2, 2, 0},
+	CapSysBoot:           {2, 2, 0},
+	CapSysNice:           {2, 2, 0},
+	CapSysResource:       {2, 2, 0},
+	CapSysTime:           {2, 2, 0},
+	CapSysTtyConfig:      {2, 2, 0},
+	CapMknod:             {2, 4, 0},
+	CapLease:             {2, 4, 0},
+	CapAuditWrite:        {2, 6, 11},
+	CapAuditControl:      {2, 6, 11},
+	CapSetfcap:           {2, 6, 24},
+	CapMacOverride:       {2, 6, 25},
+	CapMacAdmin:          {2, 6, 25},
+	CapSyslog:            {2, 6, 37},
+	CapWakeAlarm:         {3, 0, 0},
+	CapBlockSuspend:      {3, 5, 0},
+	CapAuditRead:         {3, 16, 0},
+	CapPerfmon:           {5, 8, 0},
+	CapBpf:               {5, 8, 0},
+	CapCheckpointRestore: {5, 9, 0},
+}
+
+var (
+	kernelVersionOnce sync.Once
+	kernelVersion     KernelVersion
+	kernelVersionErr  error
+)
+
+// RunningKernelVersion returns the release of the running kernel as
+// reported by uname(2).
+func RunningKernelVersion() (KernelVersion, error) {
+	kernelVersionOnce.Do(func() {
+		var uts unix.Utsname
+		if err := unix.Uname(&uts); err != nil {
+			kernelVersionErr = err
+			return
+		}
+		kernelVersion, kernelVersionErr = ParseKernelVersion(unix.ByteSliceToString(uts.Release[:]))
+	})
+	return kernelVersion, kernelVersionErr
+}
+
+// ParseKernelVersion parses a kernel release string such as
+// "5.15.0-91-generic". Anything following the numeric components is
+// ignored.
+func ParseKernelVersion(release string) (KernelVersion, error) {
+	var v KernelVersion
+	fields := strings.SplitN(release, ".", 3)
+	if len(fields) < 2 {
+		return v, fmt.Errorf("invalid kernel release %q", release)
+	}
+	parts := []*int{&v.Major, &v.Minor, &v.Patch}
+	for i, f := range fields {
+		end := 0
+		for end < len(f) && f[end] >= '0' && f[end] <= '9' {
+			end++
+		}
+		if end == 0 {
+			if i < 2 {
+				return v, fmt.Errorf("invalid kernel release %q", release)
+			}
+			break
+		}
+		n, err := strconv.Atoi(f[:end])
+		if err != nil {
+			return v, fmt.Errorf("invalid kernel release %q", release)
+		}
+		*parts[i] = n
+		if end < len(f) {
+			break
+		}
+	}
+	return v, nil
+}
+
+// KernelSupports returns true if the running kernel supports the
+// capability. A capability is supported if the running kernel release is
+// at least the release that introduced it, or if the kernel reports the
+// capability through LastCap (which covers distribution kernels with
+// backported capabilities).
+func KernelSupports(c Cap) bool {
+	if validCap(int(c)) {
+		return true
+	}
+	introduced, ok := capIntroduced[c]
+	if !ok {
+		return false
+	}
+	running, err := RunningKernelVersion()
+	if err != nil {
+		return false
+	}
+	return !running.Less(introduced)
+}
+
+// IntroducedIn returns the kernel release that introduced capability c.
+// The second result is false for capabilities unknown to this package.
+func IntroducedIn(c Cap) (KernelVersion, bool) {
+	v, ok := capIntroduced[c]
+	return v, ok
+}
@@ -0,0 +1,62 @@
+package capabilities
+
+import (
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+
+	"golang.org/x/sys/unix"
+)
+
+const capLastCapPath = "/proc/sys/kernel/cap_last_cap"
+
+var (
+	lastCapOnce sync.Once
+	lastCap     int
+)
+
+// LastCap returns the highest capability number supported by the running
+// kernel. The value is read from /proc/sys/kernel/cap_last_cap (Linux 3.2
+// and later). If the file is unavailable the bounding set is probed with
+// prctl(PR_CAPBSET_READ), and as a last resort the highest capability
+// known to this package is returned. The result is computed once and cached.
+func LastCap() int {
+	lastCapOnce.Do(func() {
+		lastCap = readLastCap(capLastCapPath)
+	})
+	return lastCap
+}
+
+func readLastCap(path string) int {
+	b, err := os.ReadFile(path)
+	if err == nil {
+		n, err := strconv.Atoi(strings.TrimSpace(string(b)))
+		if err == nil && n >= 0 {
+			return n
+		}
+	}
+	return probeLastCap()
+}
+
+// probeLastCap finds the last capability by asking the kernel whether
+// each capability is part of the bounding set. PR_CAPBSET_READ fails
+// with EINVAL for capabilities the kernel does not know about.
+func probeLastCap() int {
+	if _, err := unix.PrctlRetInt(unix.PR_CAPBSET_READ, 0, 0, 0, 0); err != nil {
+		return int(lastKnownCap)
+	}
+	n := 0
+	for {
+		_, err := unix.PrctlRetInt(unix.PR_CAPBSET_READ, uintptr(n+1), 0, 0, 0)
+		if err != nil {
+			return n
+		}
+		n++
+	}
+}
+
+// validCap returns true if capability is known to the running kernel.
+func validCap(capability int) bool {
+	return capability >= 0 && capability <= LastCap()
+}
@@ -0,0 +1,72 @@
+package capabilities
+
+import (
+	"os/exec"
+	"runtime"
+)
+
+// Launcher starts helper processes with strictly fewer capabilities than
+// the calling process. The reduced state is applied to a dedicated,
+// locked OS thread which then starts the command, so the credentials of
+// the rest of the process are left untouched. The thread is discarded
+// afterwards.
+type Launcher struct {
+	// Keep holds the capabilities the child may retain. The inheritable
+	// set is reduced to Keep and the ambient set is cleared, so a
+	// non-root child gains no capabilities beyond those granted by file
+	// capabilities or set by ConfigureCmd.
+	Keep CapSet
+	// DropBounding also removes the capabilities outside Keep from the
+	// bounding set, so that neither a root child nor programs with file
+	// capabilities can regain them. Dropping bounding capabilities
+	// requires CAP_SETPCAP.
+	DropBounding bool
+}
+
+// Reduce returns the state the launching thread switches to, derived
+// from its current state. The effective and permitted sets are kept so
+// the thread can still start the command; they do not carry over to a
+// non-root child.
+func (l *Launcher) Reduce(current *State) *State {
+	s := *current
+	s.Inheritable = s.Inheritable.Intersect(l.Keep)
+	s.Ambient = 0
+	if l.DropBounding {
+		s.Bounding = s.Bounding.Intersect(l.Keep)
+	}
+	return &s
+}
+
+// Start starts cmd with the reduced capability state. Wait for the
+// command as usual.
+func (l *Launcher) Start(cmd *exec.Cmd) error {
+	errc := make(chan error, 1)
+	go func() {
+		// The thread is not unlocked: its credentials are reduced, so
+		// it exits with the goroutine instead of returning to the
+		// scheduler.
+		runtime.LockOSThread()
+		errc <- l.start(cmd)
+	}()
+	return <-errc
+}
+
+// start applies the reduced state to the calling thread and starts cmd.
+func (l *Launcher) start(cmd *exec.Cmd) error {
+	c, err := Init()
+	if err != nil {
+		return err
+	}
+	current, err := c.GetState(0)
+	if err != nil {
+		return err
+	}
+	s := l.Reduce(current)
+	if err := s.Validate(); err != nil {
+		return err
+	}
+	if err := c.apply(s); err != nil {
+		return err
+	}
+	return cmd.Start()
+}
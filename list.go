@@ -0,0 +1,36 @@
+package capabilities
+
+import (
+	"strings"
+)
+
+// ParseCapList parses a comma separated capability list such as
+// "cap_net_admin,cap_sys_time", the format used by Docker, systemd and
+// most configuration files. Names are accepted in any form understood by
+// ParseCap and "all" selects every capability supported by the kernel.
+// White space around names and empty entries are ignored.
+func ParseCapList(list string) (CapSet, error) {
+	var caps CapSet
+	for _, name := range strings.Split(list, ",") {
+		name = strings.TrimSpace(name)
+		switch {
+		case name == "":
+			continue
+		case strings.EqualFold(name, "all"):
+			caps = caps.Union(AllCaps())
+			continue
+		}
+		c, err := ParseCap(name)
+		if err != nil {
+			return 0, err
+		}
+		caps = caps.Add(c)
+	}
+	return caps, nil
+}
+
+// FormatCapList returns caps as a comma separated list of canonical names
+// in ascending capability order, e.g. "cap_net_admin,cap_sys_time".
+func FormatCapList(caps CapSet) string {
+	return strings.Join(setNames(caps), ",")
+}
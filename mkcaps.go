@@ -0,0 +1,110 @@
+//go:build ignore
+// +build ignore
+
+// mkcaps generates zcaps.go from the kernel uapi header linux/capability.h.
+//
+// Usage:
+//
+//	go run mkcaps.go [-o zcaps.go] [/usr/include/linux/capability.h]
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"flag"
+	"fmt"
+	"go/format"
+	"log"
+	"os"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+var capDefine = regexp.MustCompile(`^#define\s+(CAP_[A-Z0-9_]+)\s+([0-9]+)\s*$`)
+
+type capability struct {
+	name  string
+	value int
+}
+
+func main() {
+	out := flag.String("o", "zcaps.go", "output file")
+	flag.Parse()
+	header := "/usr/include/linux/capability.h"
+	if flag.NArg() > 0 {
+		header = flag.Arg(0)
+	}
+	caps, err := parseHeader(header)
+	if err != nil {
+		log.Fatal(err)
+	}
+	src, err := generate(caps)
+	if err != nil {
+		log.Fatal(err)
+	}
+	if err := os.WriteFile(*out, src, 0644); err != nil {
+		log.Fatal(err)
+	}
+}
+
+func parseHeader(path string) ([]capability, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	var caps []capability
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		m := capDefine.FindStringSubmatch(strings.TrimSpace(scanner.Text()))
+		if m == nil {
+			continue
+		}
+		value, err := strconv.Atoi(m[2])
+		if err != nil {
+			return nil, err
+		}
+		if value != len(caps) {
+			return nil, fmt.Errorf("%s: %s is %d, expected %d", path, m[1], value, len(caps))
+		}
+		caps = append(caps, capability{name: m[1], value: value})
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	if len(caps) == 0 {
+		return nil, fmt.Errorf("%s: no capabilities found", path)
+	}
+	return caps, nil
+}
+
+// goName converts CAP_NET_BIND_SERVICE to CapNetBindService.
+func goName(name string) string {
+	var b strings.Builder
+	for _, part := range strings.Split(strings.ToLower(name), "_") {
+		b.WriteString(strings.ToUpper(part[:1]) + part[1:])
+	}
+	return b.String()
+}
+
+func generate(caps []capability) ([]byte, error) {
+	var b bytes.Buffer
+	fmt.Fprintf(&b, "// Code generated by mkcaps.go; DO NOT EDIT.\n\n")
+	fmt.Fprintf(&b, "package capabilities\n\n")
+	fmt.Fprintf(&b, "// Capabilities known to this package, as defined in linux/capability.h.\n")
+	fmt.Fprintf(&b, "const (\n")
+	for _, c := range caps {
+		fmt.Fprintf(&b, "\t%s Cap = %d\n", goName(c.name), c.value)
+	}
+	fmt.Fprintf(&b, ")\n\n")
+	fmt.Fprintf(&b, "// lastKnownCap is the highest capability defined in linux/capability.h.\n")
+	fmt.Fprintf(&b, "const lastKnownCap = %s\n\n", goName(caps[len(caps)-1].name))
+	fmt.Fprintf(&b, "// capNames holds the libcap name of each capability.\n")
+	fmt.Fprintf(&b, "var capNames = [...]string{\n")
+	for _, c := range caps {
+		fmt.Fprintf(&b, "\t%s: %q,\n", goName(c.name), strings.ToLower(c.name))
+	}
+	fmt.Fprintf(&b, "}\n")
+	return format.Source(b.Bytes())
+}
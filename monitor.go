@@ -0,0 +1,85 @@
+package capabilities
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+)
+
+// Monitor starts a goroutine that compares the credential state of the
+// process with the baseline captured by Baseline every interval, and
+// calls onChange whenever the drift differs from the one seen before,
+// including when the state returns to the baseline. If no baseline has
+// been captured, the current state becomes the baseline. Besides the
+// thread running the check, every thread of the process is compared
+// with the baseline capability sets, as a change on one thread is easily
+// missed otherwise. If the state cannot be read, onChange receives a
+// Drift carrying the error in Err. Monitor stops when ctx is done.
+func Monitor(ctx context.Context, interval time.Duration, onChange func(*Drift)) error {
+	baselineMu.Lock()
+	s := baseline
+	baselineMu.Unlock()
+	if s == nil {
+		var err error
+		if s, err = Baseline(); err != nil {
+			return err
+		}
+	}
+	return s.Monitor(ctx, interval, onChange)
+}
+
+// Monitor is like the package-level Monitor but compares with s.
+func (s *Snapshot) Monitor(ctx context.Context, interval time.Duration, onChange func(*Drift)) error {
+	if interval <= 0 {
+		return fmt.Errorf("invalid monitor interval %v", interval)
+	}
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		last := "no drift"
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+			}
+			d, err := s.checkAllThreads()
+			if err != nil {
+				d = &Drift{Baseline: &s.SecurityContext, Err: err}
+			}
+			if text := d.String(); text != last {
+				last = text
+				onChange(d)
+			}
+		}
+	}()
+	return nil
+}
+
+// checkAllThreads returns the drift of the calling thread, with a line
+// in Other for every thread whose capability sets differ from s.
+func (s *Snapshot) checkAllThreads() (*Drift, error) {
+	d, err := s.CheckDrift()
+	if err != nil {
+		return nil, err
+	}
+	err = withDefault(func(c *Capabilities) error {
+		threads, err := c.Threads(os.Getpid())
+		if err != nil {
+			return err
+		}
+		for _, t := range threads {
+			if t.State == s.State {
+				continue
+			}
+			ch := Change{Old: s.State, New: t.State}
+			gained, lost := ch.Gained(), ch.Lost()
+			changes := strings.Join(describeSetChanges(&gained, &lost), ", ")
+			d.Other = append(d.Other, fmt.Sprintf("thread %d: %s", t.Tid, changes))
+		}
+		return nil
+	})
+	return d, err
+}
@@ -0,0 +1,73 @@
+package capabilities
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+var capByName = func() map[string]Cap {
+	m := make(map[string]Cap, len(capNames))
+	for c, name := range capNames {
+		m[name] = Cap(c)
+	}
+	return m
+}()
+
+// ParseCap returns the capability named by name. The name is matched
+// case-insensitively and the "cap_" prefix is optional, so
+// "CAP_NET_ADMIN", "cap_net_admin" and "net_admin" all return
+// CapNetAdmin. A decimal capability number is also accepted.
+func ParseCap(name string) (Cap, error) {
+	s := strings.ToLower(strings.TrimSpace(name))
+	if n, err := strconv.Atoi(s); err == nil {
+		if n < 0 || n > 63 {
+			return 0, fmt.Errorf("capability number %d out of range", n)
+		}
+		return Cap(n), nil
+	}
+	if !strings.HasPrefix(s, "cap_") {
+		s = "cap_" + s
+	}
+	c, ok := capByName[s]
+	if !ok {
+		return 0, fmt.Errorf("unknown capability %q", name)
+	}
+	return c, nil
+}
+
+// CapFromName returns the capability named by name. It is the inverse of
+// CapName and accepts every form understood by ParseCap.
+func CapFromName(name string) (Cap, error) {
+	return ParseCap(name)
+}
+
+// NormalizeName returns the canonical lower case "cap_" prefixed form of
+// a capability name, e.g. "net_admin" becomes "cap_net_admin".
+func NormalizeName(name string) (string, error) {
+	c, err := ParseCap(name)
+	if err != nil {
+		return "", err
+	}
+	return CapName(c), nil
+}
+
+// CapName returns the canonical name of c as used by libcap, e.g.
+// "cap_net_admin". Capabilities unknown to this package are returned as
+// their decimal number.
+func CapName(c Cap) string {
+	if c >= 0 && int(c) < len(capNames) {
+		return capNames[c]
+	}
+	return strconv.Itoa(int(c))
+}
+
+// setNames returns the names of the capabilities in caps in ascending
+// order.
+func setNames(caps CapSet) []string {
+	names := make([]string, 0, caps.Len())
+	for _, c := range caps.Caps() {
+		names = append(names, CapName(c))
+	}
+	return names
+}
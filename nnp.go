@@ -0,0 +1,38 @@
+package capabilities
+
+import (
+	"golang.org/x/sys/unix"
+)
+
+// NoNewPrivs returns the no_new_privs attribute of the calling thread
+// with prctl(PR_GET_NO_NEW_PRIVS).
+func NoNewPrivs() (bool, error) {
+	v, err := unix.PrctlRetInt(unix.PR_GET_NO_NEW_PRIVS, 0, 0, 0, 0)
+	if err != nil {
+		return false, newError("get no_new_privs", 0, err)
+	}
+	return v == 1, nil
+}
+
+// SetNoNewPrivs sets the no_new_privs attribute of the calling thread
+// with prctl(PR_SET_NO_NEW_PRIVS). Once set, execve(2) no longer grants
+// privileges: set-user-ID and set-group-ID bits are ignored and file
+// capabilities cannot add to the permitted set (see ExecTransition). The
+// attribute is inherited by children and cannot be unset. It requires no
+// privileges.
+//
+// Only the calling thread is changed; see Thread for what that means in
+// a Go program. SetNoNewPrivsAllThreads changes every thread.
+func SetNoNewPrivs() error {
+	err := unix.Prctl(unix.PR_SET_NO_NEW_PRIVS, 1, 0, 0, 0)
+	return newError("set no_new_privs", 0, err)
+}
+
+// SetNoNewPrivsAllThreads sets the no_new_privs attribute of every thread
+// of the process, as SetNoNewPrivs does for the calling thread. It is not
+// available in programs built with cgo, where an error matching
+// unix.ENOTSUP is returned.
+func SetNoNewPrivsAllThreads() error {
+	err := allThreadsPrctl(unix.PR_SET_NO_NEW_PRIVS, 1)
+	return newError("set no_new_privs", 0, err)
+}
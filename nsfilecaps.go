@@ -0,0 +1,51 @@
+package capabilities
+
+import (
+	"fmt"
+)
+
+// FileCapsForNamespace returns a copy of fc that applies to root in the
+// user namespace of pid, for writing with SetFileCaps from outside the
+// namespace, e.g. to grant capabilities to a program run as root in a
+// container from the host. The copy is a revision 3 attribute whose
+// RootID is the host user ID of root in the namespace, translated
+// through /proc/<pid>/uid_map. For the initial user namespace the copy
+// is a revision 2 attribute, as the kernel would write it.
+func (c *Capabilities) FileCapsForNamespace(pid int, fc *FileCaps) (*FileCaps, error) {
+	ns, err := c.UserNamespace(pid)
+	if err != nil {
+		return nil, err
+	}
+	out := *fc
+	if ns.Initial {
+		out.Version = 2
+		out.RootID = 0
+		return &out, nil
+	}
+	rootID, ok := ns.HostUID(0)
+	if !ok {
+		return nil, fmt.Errorf("root of user namespace %d of pid %d is not mapped", ns.Inode, pid)
+	}
+	out.Version = 3
+	out.RootID = rootID
+	return &out, nil
+}
+
+// FileCapsApplyIn reports whether fc, as read from outside the user
+// namespace of pid, grants its capabilities when the file is executed in
+// that namespace. The kernel honors a revision 3 attribute only in
+// namespaces whose root, or the root of an ancestor namespace, is
+// RootID; revision 1 and 2 attributes and those with a RootID of 0 apply
+// everywhere. Namespaces between the caller's and the one of pid are not
+// considered.
+func (c *Capabilities) FileCapsApplyIn(pid int, fc *FileCaps) (bool, error) {
+	if fc.Version != 3 || fc.RootID == 0 {
+		return true, nil
+	}
+	ns, err := c.UserNamespace(pid)
+	if err != nil {
+		return false, err
+	}
+	uid, ok := ns.NamespaceUID(fc.RootID)
+	return ok && uid == 0, nil
+}
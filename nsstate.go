@@ -0,0 +1,148 @@
+package capabilities
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"golang.org/x/sys/unix"
+)
+
+// UserNSRelation is the position of the user namespace of a process
+// relative to the user namespace of the caller.
+type UserNSRelation int
+
+const (
+	// OtherUserNS is a namespace whose relation to the caller's could
+	// not be determined. It may be an ancestor of the caller's other
+	// than the initial namespace, which the kernel does not reveal, so
+	// a process in it may hold power over the caller.
+	OtherUserNS UserNSRelation = iota
+	// SameUserNS is the caller's namespace.
+	SameUserNS
+	// ChildUserNS is a namespace created, directly or indirectly, from
+	// the caller's namespace, such as that of a container.
+	ChildUserNS
+	// AncestorUserNS is a namespace the caller's was created from, such
+	// as the initial namespace seen from inside a container.
+	AncestorUserNS
+)
+
+// String returns "other", "same", "child" or "ancestor".
+func (r UserNSRelation) String() string {
+	switch r {
+	case OtherUserNS:
+		return "other"
+	case SameUserNS:
+		return "same"
+	case ChildUserNS:
+		return "child"
+	case AncestorUserNS:
+		return "ancestor"
+	}
+	return fmt.Sprintf("UserNSRelation(%d)", int(r))
+}
+
+// NamespacedState is the capability state of a process together with
+// what it means from the caller's point of view.
+type NamespacedState struct {
+	Pid int
+	// State holds the capability sets as the process sees them.
+	State State
+	// UserNS is the user namespace of the process and Relation its
+	// relation to the caller's.
+	UserNS   *UserNamespace
+	Relation UserNSRelation
+	// Towards are the effective capabilities the process can exercise
+	// over resources owned by the caller's user namespace: the
+	// effective set for SameUserNS and AncestorUserNS, and none
+	// otherwise. Root in a container with every capability has none
+	// towards the host. A process in an ancestor namespace whose
+	// effective user ID owns the caller's namespace, or one between
+	// them, holds every capability towards the caller, which Towards
+	// does not reflect.
+	Towards CapSet
+}
+
+// String describes the state, e.g.
+// "pid 812 (child user namespace 4026532205): effective=all ... towards caller: none".
+func (s *NamespacedState) String() string {
+	return fmt.Sprintf("pid %d (%v user namespace %d): %v towards caller: %s",
+		s.Pid, s.Relation, s.UserNS.Inode, &s.State, summarizeSet(s.Towards))
+}
+
+// GetNamespacedState returns the capability state of pid along with
+// its user namespace and the capabilities that are effective towards
+// the caller's user namespace, so that root in a container is not
+// mistaken for root on the host. The relation between the namespaces is
+// found with the NS_GET_PARENT ioctl (Linux 4.9 and later); if that is
+// not possible only SameUserNS and OtherUserNS are distinguished. The
+// kernel does not let the caller look up the ancestors of its own
+// namespace, so the only ancestor detected is the initial namespace and
+// other ancestors are reported as OtherUserNS. Reading the namespace of
+// a process in an ancestor namespace also requires CAP_SYS_PTRACE over
+// it, so for such processes an error matching fs.ErrPermission is
+// usually returned instead.
+func (c *Capabilities) GetNamespacedState(pid int) (*NamespacedState, error) {
+	s, err := c.GetState(pid)
+	if err != nil {
+		return nil, err
+	}
+	ns, err := c.UserNamespace(pid)
+	if err != nil {
+		return nil, err
+	}
+	self, err := c.UserNamespace(0)
+	if err != nil {
+		return nil, err
+	}
+	st := &NamespacedState{Pid: pid, State: *s, UserNS: ns}
+	switch {
+	case ns.Inode == self.Inode:
+		st.Relation = SameUserNS
+	case ns.Initial:
+		st.Relation = AncestorUserNS
+	case c.descendsFrom(pid, self.Inode):
+		st.Relation = ChildUserNS
+	}
+	if st.Relation == SameUserNS || st.Relation == AncestorUserNS {
+		st.Towards = s.Effective
+	}
+	return st, nil
+}
+
+// descendsFrom returns true if an ancestor of the user namespace of pid
+// has the inode number ancestor.
+func (c *Capabilities) descendsFrom(pid int, ancestor uint64) bool {
+	path := c.procPath(pid, "ns/user")
+	if pid == 0 {
+		path = filepath.Join(defaultProcRoot, "thread-self", "ns", "user")
+	}
+	f, err := os.Open(path)
+	if err != nil {
+		return false
+	}
+	fd, err := unix.Dup(int(f.Fd()))
+	f.Close()
+	if err != nil {
+		return false
+	}
+	for {
+		parent, err := unix.IoctlRetInt(fd, unix.NS_GET_PARENT)
+		unix.Close(fd)
+		if err != nil {
+			// EPERM once the parent is outside the caller's namespace.
+			return false
+		}
+		fd = parent
+		var st unix.Stat_t
+		if err := unix.Fstat(fd, &st); err != nil {
+			unix.Close(fd)
+			return false
+		}
+		if st.Ino == ancestor {
+			unix.Close(fd)
+			return true
+		}
+	}
+}
@@ -0,0 +1,40 @@
+package capabilities
+
+const defaultProcRoot = "/proc"
+
+// Option configures the Capabilities value returned by Init.
+type Option func(*options)
+
+type options struct {
+	version  int
+	procRoot string
+	noProbe  bool
+}
+
+// WithVersion forces the capability version (1, 2 or 3) instead of
+// probing the kernel for it.
+func WithVersion(version int) Option {
+	return func(o *options) {
+		o.version = version
+	}
+}
+
+// WithProcRoot sets the mount point of the proc filesystem used for
+// per-process queries. The default is /proc. Monitoring agents running in a container typically use
+// the host proc mounted at e.g. /host/proc. With an alternate proc root,
+// pids refer to that proc mount and every query about another process is
+// answered from /proc/<pid>/status rather than capget(2), since the
+// pids of the host may differ from those in the caller's namespace.
+func WithProcRoot(path string) Option {
+	return func(o *options) {
+		o.procRoot = path
+	}
+}
+
+// WithoutProbe skips probing the kernel for the capability version during
+// Init. Unless WithVersion is also given, version 3 is assumed.
+func WithoutProbe() Option {
+	return func(o *options) {
+		o.noProbe = true
+	}
+}
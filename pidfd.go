@@ -0,0 +1,81 @@
+package capabilities
+
+import (
+	"golang.org/x/sys/unix"
+)
+
+// PidFD is a handle on a process backed by a pidfd (Linux 5.3 and
+// later). Unlike a plain pid it cannot come to refer to another process:
+// once the process exits, queries fail with an error matching
+// ErrNoSuchProcess even if the pid has been reused. Long-lived monitors
+// should hold a PidFD rather than a pid.
+type PidFD struct {
+	c   *Capabilities
+	fd  int
+	pid int
+}
+
+// OpenPid opens a PidFD for pid with pidfd_open(2). Kernels without
+// pidfd support return an error matching ErrKernelTooOld. Close the
+// handle when done.
+func (c *Capabilities) OpenPid(pid int) (*PidFD, error) {
+	fd, err := unix.PidfdOpen(pid, 0)
+	if err != nil {
+		return nil, newError("pidfd_open", pid, err)
+	}
+	return &PidFD{c: c, fd: fd, pid: pid}, nil
+}
+
+// Pid returns the pid the handle was opened for.
+func (p *PidFD) Pid() int {
+	return p.pid
+}
+
+// Fd returns the pidfd, for use with poll(2) or other pidfd system
+// calls. It remains owned by p.
+func (p *PidFD) Fd() int {
+	return p.fd
+}
+
+// Close closes the pidfd.
+func (p *PidFD) Close() error {
+	return unix.Close(p.fd)
+}
+
+// Alive returns nil if the process is still running, or an error
+// matching ErrNoSuchProcess once it has exited.
+func (p *PidFD) Alive() error {
+	return newError("pidfd_send_signal", p.pid, unix.PidfdSendSignal(p.fd, 0, nil, 0))
+}
+
+// GetState returns all five capability sets of the process. The state
+// is read by pid and then confirmed through the pidfd: a pid cannot be
+// reused while the process is alive, so a process that is still alive
+// after the read is the one that was read.
+func (p *PidFD) GetState() (*State, error) {
+	s, err := p.c.GetState(p.pid)
+	if aliveErr := p.Alive(); aliveErr != nil {
+		return nil, aliveErr
+	}
+	return s, err
+}
+
+// Get returns the capSet CapabilitySet of the process, confirmed through
+// the pidfd as for GetState.
+func (p *PidFD) Get(capSet CapabilitySet) (CapSet, error) {
+	caps, err := p.c.Get(p.pid, capSet)
+	if aliveErr := p.Alive(); aliveErr != nil {
+		return 0, aliveErr
+	}
+	return caps, err
+}
+
+// IsSet returns true if capability is set in the capSet CapabilitySet of
+// the process, confirmed through the pidfd as for GetState.
+func (p *PidFD) IsSet(capability Cap, capSet CapabilitySet) (bool, error) {
+	set, err := p.c.IsSet(p.pid, int(capability), capSet)
+	if aliveErr := p.Alive(); aliveErr != nil {
+		return false, aliveErr
+	}
+	return set, err
+}
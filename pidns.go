@@ -0,0 +1,59 @@
+package capabilities
+
+import (
+	"os"
+	"strconv"
+	"strings"
+
+	"golang.org/x/sys/unix"
+)
+
+// ResolvePid translates pid, as seen inside the pid namespace of process
+// nsPid (e.g. any process of a container), to the pid of the same
+// process in the namespace of the proc root. It reads the NSpid field of
+// /proc/<pid>/status (Linux 4.1 and later). An error matching
+// ErrNoSuchProcess is returned if no process in that namespace has pid.
+//
+// capget(2) always looks pids up in the pid namespace the caller was
+// created in, and setns(2) into a pid namespace only affects children,
+// so translating the pid is how a query addressed in the container's
+// terms reaches the right process.
+func (c *Capabilities) ResolvePid(nsPid, pid int) (int, error) {
+	ns, err := os.Readlink(c.procPath(nsPid, "ns/pid"))
+	if err != nil {
+		return 0, newError("read pid namespace", nsPid, err)
+	}
+	entries, err := os.ReadDir(c.procDir())
+	if err != nil {
+		return 0, err
+	}
+	for _, e := range entries {
+		hostPid, err := strconv.Atoi(e.Name())
+		if err != nil {
+			continue
+		}
+		if link, err := os.Readlink(c.procPath(hostPid, "ns/pid")); err != nil || link != ns {
+			continue
+		}
+		field, err := c.readStatusField(hostPid, "NSpid")
+		if err != nil {
+			continue
+		}
+		ids := strings.Fields(field)
+		if len(ids) > 0 && ids[len(ids)-1] == strconv.Itoa(pid) {
+			return hostPid, nil
+		}
+	}
+	return 0, newError("resolve", pid, unix.ESRCH)
+}
+
+// GetStateInNamespace is like GetState but pid is interpreted in the pid
+// namespace of process nsPid, so containerized processes can be queried
+// by the pids they see themselves, e.g. pid 1 for the container's init.
+func (c *Capabilities) GetStateInNamespace(nsPid, pid int) (*State, error) {
+	hostPid, err := c.ResolvePid(nsPid, pid)
+	if err != nil {
+		return nil, err
+	}
+	return c.GetState(hostPid)
+}
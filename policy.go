@@ -0,0 +1,63 @@
+package capabilities
+
+import (
+	"fmt"
+)
+
+// Policy is an allowlist and denylist of capabilities that capability
+// states are checked against by Validate.
+type Policy struct {
+	// Allowed are the capabilities the checked sets may hold. The zero
+	// value allows none; use AllCaps for a policy that only denies.
+	Allowed CapSet
+	// Denied are the capabilities the checked sets must not hold, even
+	// if allowed.
+	Denied CapSet
+	// Sets are the capability sets checked. If empty, the effective,
+	// permitted and ambient sets are checked, which hold what a process
+	// can use now or after raising it. Add Bounding to also restrict
+	// what it could gain by executing programs.
+	Sets []CapabilitySet
+}
+
+// Violation is a capability held in breach of a Policy.
+type Violation struct {
+	Set CapabilitySet
+	Cap Cap
+	// Denied is true if Cap is in Policy.Denied, and false if it is
+	// missing from Policy.Allowed.
+	Denied bool
+}
+
+// String describes the violation, e.g.
+// "permitted set holds cap_sys_admin, which is denied".
+func (v Violation) String() string {
+	why := "not allowed"
+	if v.Denied {
+		why = "denied"
+	}
+	return fmt.Sprintf("%v set holds %v, which is %s", v.Set, v.Cap, why)
+}
+
+// Validate checks s, a live state from GetState or a proposed one,
+// against policy and returns every violation, ordered by set and
+// capability. It returns nil if s complies.
+func Validate(s *State, policy *Policy) []Violation {
+	sets := policy.Sets
+	if len(sets) == 0 {
+		sets = []CapabilitySet{Effective, Permitted, Ambient}
+	}
+	var violations []Violation
+	for _, set := range sets {
+		held := s.Set(set)
+		for _, c := range held.Caps() {
+			switch {
+			case policy.Denied.Contains(c):
+				violations = append(violations, Violation{Set: set, Cap: c, Denied: true})
+			case !policy.Allowed.Contains(c):
+				violations = append(violations, Violation{Set: set, Cap: c})
+			}
+		}
+	}
+	return violations
+}
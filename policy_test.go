@@ -0,0 +1,66 @@
+package capabilities
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestValidate(t *testing.T) {
+	s := &State{
+		Effective: NewCapSet(CapNetBindService),
+		Permitted: NewCapSet(CapNetBindService, CapSysAdmin),
+		Bounding:  NewCapSet(CapNetBindService, CapSysAdmin, CapSysModule),
+		Ambient:   NewCapSet(CapNetRaw),
+	}
+	tests := []struct {
+		name   string
+		policy Policy
+		want   []Violation
+	}{
+		{
+			name:   "compliant",
+			policy: Policy{Allowed: NewCapSet(CapNetBindService, CapSysAdmin, CapNetRaw)},
+		},
+		{
+			name:   "not allowed",
+			policy: Policy{Allowed: NewCapSet(CapNetBindService)},
+			want: []Violation{
+				{Set: Permitted, Cap: CapSysAdmin},
+				{Set: Ambient, Cap: CapNetRaw},
+			},
+		},
+		{
+			name:   "denied",
+			policy: Policy{Allowed: AllCaps(), Denied: NewCapSet(CapSysAdmin)},
+			want: []Violation{
+				{Set: Permitted, Cap: CapSysAdmin, Denied: true},
+			},
+		},
+		{
+			name:   "denied wins over allowed",
+			policy: Policy{Allowed: NewCapSet(CapNetBindService, CapNetRaw, CapSysAdmin), Denied: NewCapSet(CapNetBindService)},
+			want: []Violation{
+				{Set: Effective, Cap: CapNetBindService, Denied: true},
+				{Set: Permitted, Cap: CapNetBindService, Denied: true},
+			},
+		},
+		{
+			name: "bounding",
+			policy: Policy{
+				Allowed: NewCapSet(CapNetBindService, CapSysAdmin),
+				Sets:    []CapabilitySet{Bounding},
+			},
+			want: []Violation{
+				{Set: Bounding, Cap: CapSysModule},
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := Validate(s, &tt.policy)
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Validate = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,90 @@
+package capabilities
+
+import (
+	"fmt"
+)
+
+// EnsureCanApply checks whether Apply would succeed for s on the calling
+// thread without changing anything. It returns an *CannotApplyError listing
+// every reason found, such as a missing CAP_SETPCAP, an ambient
+// capability outside the permitted, inheritable or bounding set, a locked
+// securebit or no_new_privs preventing permitted capabilities from being
+// regained, rather than the single EPERM Apply would fail with.
+// Capabilities of s.Bounding that were already dropped are not a reason:
+// Apply leaves them out of the bounding set without failing.
+func (s *State) EnsureCanApply() error {
+	return withDefault(func(c *Capabilities) error {
+		reasons, err := c.applyBlockers(s)
+		if err != nil {
+			return err
+		}
+		if len(reasons) > 0 {
+			return &CannotApplyError{Reasons: reasons}
+		}
+		return nil
+	})
+}
+
+// applyBlockers returns the reasons c.apply(s) would fail, following the
+// checks made by the kernel for PR_CAPBSET_DROP, capset(2) and
+// PR_CAP_AMBIENT_RAISE in the order apply makes them.
+func (c *Capabilities) applyBlockers(s *State) ([]string, error) {
+	var reasons []string
+	if err := s.Validate(); err != nil {
+		reasons = append(reasons, err.Error())
+	}
+	if c.Version == 1 && s.Effective|s.Permitted|s.Inheritable > 0xffffffff {
+		reasons = append(reasons, "capabilities above 31 not supported for capability v1")
+	}
+	cur, err := c.GetState(0)
+	if err != nil {
+		return nil, err
+	}
+	nnp, err := NoNewPrivs()
+	if err != nil {
+		return nil, err
+	}
+	bits, err := GetSecurebits()
+	if err != nil {
+		return nil, err
+	}
+	setpcap := cur.Effective.Contains(CapSetpcap)
+
+	// Bounding set.
+	if !cur.Bounding.Subtract(s.Bounding).IsEmpty() && !setpcap {
+		reasons = append(reasons, "dropping bounding capabilities requires CAP_SETPCAP in the effective set")
+	}
+	bounding := cur.Bounding.Intersect(s.Bounding)
+
+	// Permitted and inheritable sets.
+	if gain := s.Permitted.Subtract(cur.Permitted); !gain.IsEmpty() {
+		reason := fmt.Sprintf("permitted capabilities %v are not held and cannot be added", gain.Caps())
+		if nnp {
+			reason += ", and no_new_privs prevents regaining them through execve"
+		}
+		reasons = append(reasons, reason)
+	}
+	if !setpcap {
+		if gain := s.Inheritable.Subtract(cur.Inheritable.Union(cur.Permitted)); !gain.IsEmpty() {
+			reasons = append(reasons, fmt.Sprintf("inheritable capabilities %v are not permitted and CAP_SETPCAP is not in the effective set", gain.Caps()))
+		}
+	}
+	if gain := s.Inheritable.Subtract(cur.Inheritable.Union(bounding)); !gain.IsEmpty() {
+		reasons = append(reasons, fmt.Sprintf("inheritable capabilities %v are not in the bounding set", gain.Caps()))
+	}
+
+	// Ambient set.
+	// Validate has reported ambient capabilities outside the permitted
+	// and inheritable sets.
+	if extra := s.Ambient.Intersect(s.Permitted).Intersect(s.Inheritable).Subtract(bounding); !extra.IsEmpty() {
+		reasons = append(reasons, fmt.Sprintf("ambient capabilities %v are not in the bounding set", extra.Caps()))
+	}
+	if !s.Ambient.IsEmpty() && bits&SecbitNoCapAmbientRaise != 0 {
+		reason := "ambient capabilities cannot be raised with SECBIT_NO_CAP_AMBIENT_RAISE set"
+		if bits&SecbitNoCapAmbientRaiseLocked != 0 {
+			reason += " and locked"
+		}
+		reasons = append(reasons, reason)
+	}
+	return reasons, nil
+}
@@ -0,0 +1,64 @@
+package capabilities
+
+// lockedProfile returns a profile keeping only required, with every
+// other capability removed from all sets including the bounding set,
+// the securebits Harden sets by default locked and no_new_privs set.
+func lockedProfile(name string, required ...Cap) *Profile {
+	keep := NewCapSet(required...)
+	return &Profile{
+		Name:           name,
+		Required:       keep,
+		Drop:           AllCaps().Subtract(keep),
+		Securebits:     defaultHardenSecurebits,
+		LockSecurebits: true,
+		NoNewPrivs:     true,
+		Bounding:       BoundingRequiredOnly,
+	}
+}
+
+// WebServerProfile returns a profile for a server binding ports below
+// 1024: it keeps CAP_NET_BIND_SERVICE only and locks the process down.
+// Each call returns a new Profile that can be adjusted before Activate.
+//
+// The profile drops CAP_SETPCAP after using it to lock the process down,
+// as do PacketCaptureProfile and TimeSyncProfile. Activate them as root
+// or with CAP_SETPCAP effective; otherwise Activate fails before
+// changing anything.
+func WebServerProfile() *Profile {
+	return lockedProfile("web-server", CapNetBindService)
+}
+
+// PacketCaptureProfile returns a profile for packet capture tools such
+// as tcpdump: CAP_NET_RAW to open packet sockets and CAP_NET_ADMIN to
+// enable promiscuous mode.
+func PacketCaptureProfile() *Profile {
+	return lockedProfile("packet-capture", CapNetRaw, CapNetAdmin)
+}
+
+// TimeSyncProfile returns a profile for NTP daemons: CAP_SYS_TIME to set
+// the clock and CAP_NET_BIND_SERVICE for port 123.
+func TimeSyncProfile() *Profile {
+	return lockedProfile("time-sync", CapSysTime, CapNetBindService)
+}
+
+// ContainerRuntimeProfile returns a profile for container runtimes. It
+// keeps the capabilities needed to set up namespaces, mounts, devices
+// and the credentials of containers, and drops those that reach beyond
+// them such as CAP_SYS_MODULE, CAP_SYS_TIME and CAP_SYS_BOOT. The
+// bounding set, securebits and no_new_privs are left alone, as the
+// runtime passes capabilities on to container processes and configures
+// those itself.
+func ContainerRuntimeProfile() *Profile {
+	keep := NewCapSet(
+		CapChown, CapDacOverride, CapFowner, CapFsetid, CapKill,
+		CapSetgid, CapSetuid, CapSetpcap, CapNetBindService, CapNetAdmin,
+		CapNetRaw, CapSysChroot, CapSysAdmin, CapSysPtrace, CapSysResource,
+		CapMknod, CapAuditWrite, CapSetfcap,
+	)
+	return &Profile{
+		Name:     "container-runtime",
+		Required: keep,
+		Drop:     AllCaps().Subtract(keep),
+		Bounding: BoundingKeep,
+	}
+}
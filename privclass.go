@@ -0,0 +1,89 @@
+package capabilities
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+)
+
+// PrivilegeClass classifies the privileges of a process by its effective
+// user ID, user namespace and capabilities.
+type PrivilegeClass int
+
+const (
+	// Unprivileged is a non-root process without capabilities.
+	Unprivileged PrivilegeClass = iota
+	// UnprivilegedWithCaps is a non-root process holding capabilities
+	// in its effective, permitted or ambient set.
+	UnprivilegedWithCaps
+	// UserNSRoot is root in a user namespace other than the initial
+	// one, e.g. in a rootless container. Its capabilities do not reach
+	// the host.
+	UserNSRoot
+	// InitNSRoot is root in the initial user namespace: real root.
+	InitNSRoot
+)
+
+// String returns "unprivileged", "unprivileged-with-caps", "userns-root"
+// or "init-ns-root".
+func (pc PrivilegeClass) String() string {
+	switch pc {
+	case Unprivileged:
+		return "unprivileged"
+	case UnprivilegedWithCaps:
+		return "unprivileged-with-caps"
+	case UserNSRoot:
+		return "userns-root"
+	case InitNSRoot:
+		return "init-ns-root"
+	}
+	return fmt.Sprintf("PrivilegeClass(%d)", int(pc))
+}
+
+// classify returns the privilege class of p. A root process whose user
+// namespace is unknown is classified as InitNSRoot, the worse case.
+// The user IDs in /proc/<pid>/status are those of the reader's user
+// namespace, so root in a container appears as the host user ID it is
+// mapped to. uidMap is the uid_map of the user namespace of p, read by a
+// caller outside that namespace, and translates the ID back; it is nil
+// otherwise.
+func (p *Process) classify(uidMap []IDMapping) PrivilegeClass {
+	userNS := p.UserNSInode != 0 && p.UserNSInode != initUserNSInode
+	if userNS && uidMap != nil {
+		ns := UserNamespace{UIDMap: uidMap}
+		if uid, ok := ns.NamespaceUID(uint32(p.EUID)); ok && uid == 0 {
+			return UserNSRoot
+		}
+	}
+	if p.EUID == 0 {
+		if userNS {
+			return UserNSRoot
+		}
+		return InitNSRoot
+	}
+	s := &p.State
+	if s.Effective.IsEmpty() && s.Permitted.IsEmpty() && s.Ambient.IsEmpty() {
+		return Unprivileged
+	}
+	return UnprivilegedWithCaps
+}
+
+// classUIDMap returns the uid_map classify needs for p: that of its user
+// namespace if it is neither the initial namespace nor the caller's.
+func (c *Capabilities) classUIDMap(p *Process) []IDMapping {
+	if p.UserNSInode == 0 || p.UserNSInode == initUserNSInode {
+		return nil
+	}
+	var self uint64
+	if link, err := os.Readlink(filepath.Join(defaultProcRoot, "self", "ns", "user")); err == nil {
+		fmt.Sscanf(link, "user:[%d]", &self)
+	}
+	if p.UserNSInode == self {
+		return nil
+	}
+	uidMap, err := readIDMap(c.procPath(p.Pid, "uid_map"))
+	if err != nil {
+		return nil
+	}
+	return uidMap
+}
@@ -0,0 +1,118 @@
+package capabilities
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// writeFakeProcess creates /proc/<pid> entries for a process under root.
+func writeFakeProcess(t *testing.T, root string, pid int, euid int, capEff CapSet, userNS uint64, uidMap string) {
+	t.Helper()
+	dir := filepath.Join(root, fmt.Sprint(pid))
+	if err := os.MkdirAll(filepath.Join(dir, "ns"), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	status := fmt.Sprintf("Name:\tfake\nPPid:\t1\nUid:\t%d\t%d\t%d\t%d\n"+
+		"CapInh:\t0000000000000000\nCapPrm:\t%016x\nCapEff:\t%016x\nCapBnd:\t%016x\nCapAmb:\t0000000000000000\n",
+		euid, euid, euid, euid, uint64(capEff), uint64(capEff), uint64(AllCaps()))
+	if err := os.WriteFile(filepath.Join(dir, "status"), []byte(status), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Symlink(fmt.Sprintf("user:[%d]", userNS), filepath.Join(dir, "ns", "user")); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, "uid_map"), []byte(uidMap), 0o644); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestProcessClass(t *testing.T) {
+	// An inode no real namespace of the test process has.
+	const containerNS = 4026599999
+	tests := []struct {
+		name   string
+		euid   int
+		caps   CapSet
+		userNS uint64
+		uidMap string
+		want   PrivilegeClass
+	}{
+		{
+			name:   "host root",
+			caps:   AllCaps(),
+			userNS: initUserNSInode,
+			uidMap: "0 0 4294967295\n",
+			want:   InitNSRoot,
+		},
+		{
+			name:   "host user",
+			euid:   1000,
+			userNS: initUserNSInode,
+			uidMap: "0 0 4294967295\n",
+			want:   Unprivileged,
+		},
+		{
+			name:   "host user with capabilities",
+			euid:   1000,
+			caps:   NewCapSet(CapNetBindService),
+			userNS: initUserNSInode,
+			uidMap: "0 0 4294967295\n",
+			want:   UnprivilegedWithCaps,
+		},
+		{
+			name:   "container root seen from the host",
+			euid:   100000,
+			caps:   AllCaps(),
+			userNS: containerNS,
+			uidMap: "0 100000 65536\n",
+			want:   UserNSRoot,
+		},
+		{
+			name:   "rootless container root seen from the host",
+			euid:   1000,
+			caps:   AllCaps(),
+			userNS: containerNS,
+			uidMap: "0 1000 1\n",
+			want:   UserNSRoot,
+		},
+		{
+			name:   "container user seen from the host",
+			euid:   101000,
+			caps:   NewCapSet(CapNetBindService),
+			userNS: containerNS,
+			uidMap: "0 100000 65536\n",
+			want:   UnprivilegedWithCaps,
+		},
+		{
+			name:   "container root seen from inside",
+			caps:   AllCaps(),
+			userNS: containerNS,
+			want:   UserNSRoot,
+		},
+		{
+			name: "unknown namespace",
+			caps: AllCaps(),
+			want: InitNSRoot,
+		},
+	}
+	root := t.TempDir()
+	c, err := Init(WithProcRoot(root))
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			pid := 1000 + i
+			writeFakeProcess(t, root, pid, tt.euid, tt.caps, tt.userNS, tt.uidMap)
+			p, err := c.readProcess(pid)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if p.Class != tt.want {
+				t.Errorf("Class = %v, want %v", p.Class, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,85 @@
+package capabilities
+
+import (
+	"fmt"
+	"syscall"
+	"unsafe"
+
+	"golang.org/x/sys/unix"
+)
+
+// DropPrivileges switches every thread of the process to uid and gid
+// while retaining the capabilities in keep, the usual way for a daemon
+// started as root to give up root. It performs the complete keepcaps
+// sequence on all threads:
+//
+//  1. prctl(PR_SET_KEEPCAPS, 1), so the permitted set survives the
+//     change of user ID
+//  2. setgroups(2) with an empty list, setresgid(2) and setresuid(2)
+//  3. capset(2) with keep as the effective and permitted sets and an
+//     empty inheritable set, re-raising the effective capabilities the
+//     kernel cleared on the change of user ID and dropping the rest
+//  4. prctl(PR_SET_KEEPCAPS, 0)
+//
+// The bounding set is not changed. keep must be a subset of the
+// permitted set of the calling thread.
+//
+// Capabilities are set on all threads with syscall.AllThreadsSyscall,
+// which is not available in programs built with cgo; in that case an
+// error matching unix.ENOTSUP is returned before anything is changed.
+func DropPrivileges(uid, gid int, keep ...Cap) error {
+	want := NewCapSet(keep...)
+	permitted, err := Get(Permitted)
+	if err != nil {
+		return err
+	}
+	if missing := want.Subtract(permitted); !missing.IsEmpty() {
+		return fmt.Errorf("capabilities %v not in permitted set", missing.Caps())
+	}
+	if err := allThreadsPrctl(unix.PR_SET_KEEPCAPS, 1); err != nil {
+		return newError("set keepcaps", 0, err)
+	}
+	if err := syscall.Setgroups(nil); err != nil {
+		return newError("setgroups", 0, err)
+	}
+	if err := syscall.Setresgid(gid, gid, gid); err != nil {
+		return newError("setresgid", 0, err)
+	}
+	if err := syscall.Setresuid(uid, uid, uid); err != nil {
+		return newError("setresuid", 0, err)
+	}
+	if err := allThreadsCapset(&State{Effective: want, Permitted: want}); err != nil {
+		return newError("capset", 0, err)
+	}
+	if err := allThreadsPrctl(unix.PR_SET_KEEPCAPS, 0); err != nil {
+		return newError("clear keepcaps", 0, err)
+	}
+	return nil
+}
+
+// allThreadsPrctl calls prctl(option, arg2) on every thread.
+func allThreadsPrctl(option, arg2 uintptr) error {
+	_, _, errno := syscall.AllThreadsSyscall(unix.SYS_PRCTL, option, arg2, 0)
+	if errno != 0 {
+		return errno
+	}
+	return nil
+}
+
+// allThreadsCapset sets the effective, permitted and inheritable sets of
+// every thread to those of s.
+func allThreadsCapset(s *State) error {
+	header := unix.CapUserHeader{Version: unix.LINUX_CAPABILITY_VERSION_3}
+	var data [2]unix.CapUserData
+	for i := range data {
+		shift := uint(32 * i)
+		data[i].Effective = uint32(s.Effective >> shift)
+		data[i].Permitted = uint32(s.Permitted >> shift)
+		data[i].Inheritable = uint32(s.Inheritable >> shift)
+	}
+	_, _, errno := syscall.AllThreadsSyscall(unix.SYS_CAPSET, uintptr(unsafe.Pointer(&header)), uintptr(unsafe.Pointer(&data[0])), 0)
+	if errno != 0 {
+		return errno
+	}
+	return nil
+}
@@ -0,0 +1,88 @@
+package capabilities
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+// procDir returns the proc root of c.
+func (c *Capabilities) procDir() string {
+	if c.procRoot == "" {
+		return defaultProcRoot
+	}
+	return c.procRoot
+}
+
+// procPath returns the path of name under the proc directory of pid.
+func (c *Capabilities) procPath(pid int, name string) string {
+	return filepath.Join(c.procDir(), strconv.Itoa(pid), name)
+}
+
+// isSelf returns true if pid refers to the calling process: pid 0, or
+// its own pid when the proc root is /proc.
+func (c *Capabilities) isSelf(pid int) bool {
+	return pid == 0 || (c.procDir() == defaultProcRoot && pid == os.Getpid())
+}
+
+// errFieldNotFound is returned by readStatusField when the status file
+// has no such field.
+var errFieldNotFound = errors.New("not found")
+
+// readStatusField returns the value of field from /proc/<pid>/status.
+func (c *Capabilities) readStatusField(pid int, field string) (string, error) {
+	f, err := os.Open(c.procPath(pid, "status"))
+	if err != nil {
+		return "", err
+	}
+	defer f.Close()
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		key, value, ok := cut(scanner.Text(), ":")
+		if ok && key == field {
+			return strings.TrimSpace(value), nil
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return "", err
+	}
+	return "", fmt.Errorf("%s %w in %s", field, errFieldNotFound, f.Name())
+}
+
+// parseHexWords parses a hex capability mask such as "000001ffffffffff"
+// into words of 32 bits, least significant word first. Words beyond the
+// length of the mask are cleared.
+func parseHexWords(mask string, words []uint32) error {
+	for i := range words {
+		words[i] = 0
+	}
+	for i := 0; len(mask) > 0; i++ {
+		start := len(mask) - 8
+		if start < 0 {
+			start = 0
+		}
+		w, err := strconv.ParseUint(mask[start:], 16, 32)
+		if err != nil {
+			return fmt.Errorf("invalid capability mask %q", mask)
+		}
+		if i < len(words) {
+			words[i] = uint32(w)
+		} else if w != 0 {
+			return fmt.Errorf("capability mask %q exceeds %d bits", mask, 32*len(words))
+		}
+		mask = mask[:start]
+	}
+	return nil
+}
+
+// cut slices s around the first instance of sep.
+func cut(s, sep string) (before, after string, found bool) {
+	if i := strings.Index(s, sep); i >= 0 {
+		return s[:i], s[i+len(sep):], true
+	}
+	return s, "", false
+}
@@ -0,0 +1,195 @@
+package capabilities
+
+import (
+	"bufio"
+	"bytes"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+// Process describes a process found by ScanProcesses.
+type Process struct {
+	Pid  int
+	PPid int
+	// Name is the command name from /proc/<pid>/status.
+	Name string
+	// Cmdline holds the command line arguments. It is empty for kernel
+	// threads and zombies.
+	Cmdline []string
+	// UID and EUID are the real and effective user IDs.
+	UID  int
+	EUID int
+	// NoNewPrivs and Seccomp determine whether the capabilities of the
+	// process can grow on execve(2).
+	NoNewPrivs bool
+	Seccomp    SeccompMode
+	// Securebits holds the securebits flags if the kernel reports them
+	// in the SecBits status field, as indicated by HaveSecurebits.
+	// Mainline kernels do not.
+	Securebits     Securebits
+	HaveSecurebits bool
+	// UserNSInode identifies the user namespace of the process, or is 0
+	// if it could not be read.
+	UserNSInode uint64
+	// Class classifies the privileges of the process.
+	Class PrivilegeClass
+	// State holds the capability sets of the process.
+	State State
+}
+
+// ProcessFilter selects the processes reported by ScanProcesses.
+type ProcessFilter struct {
+	// Caps are the capabilities a process must hold. An empty set
+	// matches every process.
+	Caps CapSet
+	// Set is the capability set searched. The zero value searches the
+	// effective set.
+	Set CapabilitySet
+	// Any makes a process match if it holds any of Caps rather than all
+	// of them.
+	Any bool
+}
+
+// Match returns true if s satisfies the filter.
+func (f *ProcessFilter) Match(s *State) bool {
+	held := s.Set(f.Set)
+	if f.Any && !f.Caps.IsEmpty() {
+		return !held.Intersect(f.Caps).IsEmpty()
+	}
+	return held.ContainsAll(f.Caps)
+}
+
+// ScanProcesses walks the proc root and returns every process matching
+// filter, answering questions such as "which processes on this host have
+// CAP_SYS_ADMIN". Processes that exit during the scan or cannot be read
+// are skipped.
+func (c *Capabilities) ScanProcesses(filter ProcessFilter) ([]Process, error) {
+	entries, err := os.ReadDir(c.procDir())
+	if err != nil {
+		return nil, err
+	}
+	var pids []int
+	for _, e := range entries {
+		if pid, err := strconv.Atoi(e.Name()); err == nil && e.IsDir() {
+			pids = append(pids, pid)
+		}
+	}
+	return c.scanPids(pids, filter), nil
+}
+
+// scanPids returns the processes in pids matching filter.
+func (c *Capabilities) scanPids(pids []int, filter ProcessFilter) []Process {
+	var found []Process
+	for _, pid := range pids {
+		p, err := c.readProcess(pid)
+		if err != nil || !filter.Match(&p.State) {
+			continue
+		}
+		found = append(found, *p)
+	}
+	return found
+}
+
+// readProcess reads the status and command line of pid.
+func (c *Capabilities) readProcess(pid int) (*Process, error) {
+	status, err := os.ReadFile(c.procPath(pid, "status"))
+	if err != nil {
+		return nil, newError("read status", pid, err)
+	}
+	s, err := ParseProcStatus(bytes.NewReader(status))
+	if err != nil {
+		return nil, newError("read status", pid, err)
+	}
+	p := &Process{Pid: pid, State: *s}
+	scanner := bufio.NewScanner(bytes.NewReader(status))
+	for scanner.Scan() {
+		key, value, ok := cut(scanner.Text(), ":")
+		if !ok {
+			continue
+		}
+		value = strings.TrimSpace(value)
+		switch key {
+		case "Name":
+			p.Name = value
+		case "PPid":
+			p.PPid, _ = strconv.Atoi(value)
+		case "Uid":
+			// Real, effective, saved set and file system UIDs.
+			ids := strings.Fields(value)
+			if len(ids) >= 2 {
+				p.UID, _ = strconv.Atoi(ids[0])
+				p.EUID, _ = strconv.Atoi(ids[1])
+			}
+		case "NoNewPrivs":
+			p.NoNewPrivs = parseFlag(strings.Fields(value))
+		case "Seccomp":
+			p.Seccomp = parseSeccomp(strings.Fields(value))
+		case "SecBits":
+			p.Securebits, p.HaveSecurebits = parseSecurebits(strings.Fields(value))
+		}
+	}
+	if link, err := os.Readlink(c.procPath(pid, "ns/user")); err == nil {
+		fmt.Sscanf(link, "user:[%d]", &p.UserNSInode)
+	}
+	p.Class = p.classify(c.classUIDMap(p))
+	if cmdline, err := os.ReadFile(c.procPath(pid, "cmdline")); err == nil {
+		cmdline = bytes.TrimRight(cmdline, "\x00")
+		if len(cmdline) > 0 {
+			p.Cmdline = strings.Split(string(cmdline), "\x00")
+		}
+	}
+	return p, nil
+}
+
+// ScanCgroup is like ScanProcesses but only considers the processes in
+// the cgroup directory dir and its descendants, e.g.
+// /sys/fs/cgroup/system.slice/docker-<id>.scope for a container. Both
+// cgroup v1 hierarchies and the cgroup v2 unified hierarchy are
+// supported, as both list member processes in cgroup.procs.
+func (c *Capabilities) ScanCgroup(dir string, filter ProcessFilter) ([]Process, error) {
+	pids, err := cgroupPids(dir)
+	if err != nil {
+		return nil, err
+	}
+	return c.scanPids(pids, filter), nil
+}
+
+// cgroupPids returns the processes in the cgroup directory dir and its
+// descendants.
+func cgroupPids(dir string) ([]int, error) {
+	seen := make(map[int]bool)
+	var pids []int
+	err := filepath.WalkDir(dir, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			// Child cgroups may be removed during the walk.
+			if path != dir && errors.Is(err, fs.ErrNotExist) {
+				return nil
+			}
+			return err
+		}
+		if !d.IsDir() {
+			return nil
+		}
+		procs, err := os.ReadFile(filepath.Join(path, "cgroup.procs"))
+		if err != nil {
+			if path != dir && errors.Is(err, fs.ErrNotExist) {
+				return nil
+			}
+			return err
+		}
+		for _, field := range strings.Fields(string(procs)) {
+			pid, err := strconv.Atoi(field)
+			if err == nil && !seen[pid] {
+				seen[pid] = true
+				pids = append(pids, pid)
+			}
+		}
+		return nil
+	})
+	return pids, err
+}
@@ -0,0 +1,113 @@
+package capabilities
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+)
+
+// statusFields maps the capability fields of /proc/<pid>/status to the
+// set they describe.
+var statusFields = map[string]CapabilitySet{
+	"CapInh": Inheritable,
+	"CapPrm": Permitted,
+	"CapEff": Effective,
+	"CapBnd": Bounding,
+	"CapAmb": Ambient,
+}
+
+// ParseProcStatus reads the CapInh, CapPrm, CapEff, CapBnd and CapAmb
+// lines of a /proc/<pid>/status file (or text pasted from one) and
+// returns the State they describe. Other lines are ignored, as is
+// leading white space. CapInh, CapPrm and CapEff are required; CapBnd and
+// CapAmb are absent on older kernels and leave the set empty.
+func ParseProcStatus(r io.Reader) (*State, error) {
+	var s State
+	seen := make(map[CapabilitySet]bool)
+	scanner := bufio.NewScanner(r)
+	for scanner.Scan() {
+		key, value, ok := cut(strings.TrimSpace(scanner.Text()), ":")
+		if !ok {
+			continue
+		}
+		capSet, ok := statusFields[key]
+		if !ok {
+			continue
+		}
+		mask, err := ParseHexCapSet(value)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", key, err)
+		}
+		s.setMask(capSet, mask)
+		seen[capSet] = true
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	for _, capSet := range []CapabilitySet{Inheritable, Permitted, Effective} {
+		if !seen[capSet] {
+			return nil, fmt.Errorf("missing %v capabilities in status", capSet)
+		}
+	}
+	return &s, nil
+}
+
+// setMask replaces the capSet set of s with caps.
+func (s *State) setMask(capSet CapabilitySet, caps CapSet) {
+	switch capSet {
+	case Effective:
+		s.Effective = caps
+	case Permitted:
+		s.Permitted = caps
+	case Inheritable:
+		s.Inheritable = caps
+	case Bounding:
+		s.Bounding = caps
+	case Ambient:
+		s.Ambient = caps
+	}
+}
+
+// ReadProcStatus returns the capability state of pid as reported by
+// /proc/<pid>/status under the configured proc root.
+func (c *Capabilities) ReadProcStatus(pid int) (*State, error) {
+	f, err := os.Open(c.procPath(pid, "status"))
+	if err != nil {
+		return nil, newError("read status", pid, err)
+	}
+	defer f.Close()
+	return ParseProcStatus(f)
+}
+
+// Hex returns caps as the 16 digit hex mask used by /proc/<pid>/status
+// and capsh --decode, e.g. "0000000000003000".
+func (s CapSet) Hex() string {
+	return fmt.Sprintf("%016x", uint64(s))
+}
+
+// ParseHexCapSet parses a hex mask as produced by CapSet.Hex. A leading
+// "0x" is accepted.
+func ParseHexCapSet(mask string) (CapSet, error) {
+	m := strings.TrimPrefix(strings.TrimPrefix(strings.TrimSpace(mask), "0x"), "0X")
+	v, err := strconv.ParseUint(m, 16, 64)
+	if err != nil {
+		return 0, fmt.Errorf("invalid capability mask %q", mask)
+	}
+	return CapSet(v), nil
+}
+
+// ProcStatus renders s as the capability lines of /proc/<pid>/status, in
+// the order the kernel prints them:
+//
+//	CapInh:	0000000000000000
+//	CapPrm:	0000000000003000
+//	CapEff:	0000000000003000
+//	CapBnd:	000001ffffffffff
+//	CapAmb:	0000000000000000
+func (s *State) ProcStatus() string {
+	return fmt.Sprintf("CapInh:\t%s\nCapPrm:\t%s\nCapEff:\t%s\nCapBnd:\t%s\nCapAmb:\t%s\n",
+		s.Inheritable.Hex(), s.Permitted.Hex(), s.Effective.Hex(), s.Bounding.Hex(), s.Ambient.Hex())
+}
@@ -0,0 +1,124 @@
+package capabilities
+
+import (
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+)
+
+// ProcessNode is a process in the tree built by ProcessTree.
+type ProcessNode struct {
+	Process
+	// Parent is nil for the roots of the tree.
+	Parent   *ProcessNode
+	Children []*ProcessNode
+}
+
+// Dropped returns the permitted capabilities the parent holds but the
+// process does not, showing where privileges were given up.
+func (n *ProcessNode) Dropped() CapSet {
+	if n.Parent == nil {
+		return 0
+	}
+	return n.Parent.State.Permitted.Subtract(n.State.Permitted)
+}
+
+// Gained returns the permitted capabilities the process holds but its
+// parent does not, for example through file capabilities.
+func (n *ProcessNode) Gained() CapSet {
+	if n.Parent == nil {
+		return 0
+	}
+	return n.State.Permitted.Subtract(n.Parent.State.Permitted)
+}
+
+// ProcessTree reads every process under the proc root and links them into
+// a tree by parent pid. The roots, usually init and kthreadd, are
+// returned with children sorted by pid. Processes whose parent could not
+// be read become roots themselves.
+func (c *Capabilities) ProcessTree() ([]*ProcessNode, error) {
+	procs, err := c.ScanProcesses(ProcessFilter{})
+	if err != nil {
+		return nil, err
+	}
+	nodes := make(map[int]*ProcessNode, len(procs))
+	for _, p := range procs {
+		nodes[p.Pid] = &ProcessNode{Process: p}
+	}
+	var roots []*ProcessNode
+	for _, p := range procs {
+		n := nodes[p.Pid]
+		if parent, ok := nodes[p.PPid]; ok && p.PPid != p.Pid {
+			n.Parent = parent
+			parent.Children = append(parent.Children, n)
+		} else {
+			roots = append(roots, n)
+		}
+	}
+	sortNodes(roots)
+	for _, n := range nodes {
+		sortNodes(n.Children)
+	}
+	return roots, nil
+}
+
+func sortNodes(nodes []*ProcessNode) {
+	sort.Slice(nodes, func(i, j int) bool {
+		return nodes[i].Pid < nodes[j].Pid
+	})
+}
+
+// RenderProcessTree writes roots and their descendants as an indented
+// tree, one process per line with its capabilities in the text format of
+// getpcaps(8) and the permitted capabilities dropped or gained relative
+// to its parent:
+//
+//	1 systemd: =ep
+//	└─ 812 sshd: =ep
+//	   └─ 2071 bash: = (dropped all)
+func RenderProcessTree(w io.Writer, roots []*ProcessNode) error {
+	for _, n := range roots {
+		if err := renderNode(w, n, "", ""); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// renderNode writes n with the given prefix and its children below it.
+func renderNode(w io.Writer, n *ProcessNode, prefix, childPrefix string) error {
+	line := fmt.Sprintf("%s%d %s: %s", prefix, n.Pid, n.Name, n.State.Text())
+	var changes []string
+	if dropped := n.Dropped(); !dropped.IsEmpty() {
+		changes = append(changes, "dropped "+summarizeChange(dropped))
+	}
+	if gained := n.Gained(); !gained.IsEmpty() {
+		changes = append(changes, "gained "+summarizeChange(gained))
+	}
+	if len(changes) > 0 {
+		line += " (" + strings.Join(changes, ", ") + ")"
+	}
+	if _, err := fmt.Fprintln(w, line); err != nil {
+		return err
+	}
+	for i, child := range n.Children {
+		branch, indent := "├─ ", "│  "
+		if i == len(n.Children)-1 {
+			branch, indent = "└─ ", "   "
+		}
+		if err := renderNode(w, child, childPrefix+branch, childPrefix+indent); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// summarizeChange renders caps as a capability list, or "all" if it
+// holds every capability supported by the kernel.
+func summarizeChange(caps CapSet) string {
+	if caps == AllCaps() {
+		return "all"
+	}
+	return FormatCapList(caps)
+}
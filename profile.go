@@ -0,0 +1,155 @@
+package capabilities
+
+import (
+	"fmt"
+
+	"golang.org/x/sys/unix"
+)
+
+// BoundingPolicy selects what Profile.Activate does with the bounding
+// set.
+type BoundingPolicy int
+
+const (
+	// BoundingKeep leaves the bounding set unchanged.
+	BoundingKeep BoundingPolicy = iota
+	// BoundingDrop removes the capabilities of Profile.Drop from the
+	// bounding set.
+	BoundingDrop
+	// BoundingRequiredOnly reduces the bounding set to
+	// Profile.Required.
+	BoundingRequiredOnly
+)
+
+// String returns "keep", "drop" or "required-only".
+func (p BoundingPolicy) String() string {
+	switch p {
+	case BoundingKeep:
+		return "keep"
+	case BoundingDrop:
+		return "drop"
+	case BoundingRequiredOnly:
+		return "required-only"
+	}
+	return fmt.Sprintf("BoundingPolicy(%d)", int(p))
+}
+
+// Profile declares the privileges of a program, to be activated first
+// thing in main. Plan computes the resulting capability state without
+// side effects, so a profile can be checked in tests.
+type Profile struct {
+	// Name identifies the profile in errors.
+	Name string
+	// Required are the capabilities the program needs. They must be
+	// permitted and are raised in the effective set.
+	Required CapSet
+	// Drop are the capabilities removed from the effective, permitted,
+	// inheritable and ambient sets.
+	Drop CapSet
+	// Securebits are set if non-zero. LockSecurebits also locks every
+	// flag that is set.
+	Securebits     Securebits
+	LockSecurebits bool
+	// NoNewPrivs sets no_new_privs.
+	NoNewPrivs bool
+	// Bounding selects what happens to the bounding set.
+	Bounding BoundingPolicy
+}
+
+// Validate checks the profile for contradictions.
+func (p *Profile) Validate() error {
+	if both := p.Required.Intersect(p.Drop); !both.IsEmpty() {
+		return fmt.Errorf("profile %s: capabilities %v both required and dropped", p.Name, both.Caps())
+	}
+	if p.Securebits&^(securebitFlags|securebitFlags<<1) != 0 {
+		return fmt.Errorf("profile %s: unknown securebits %v", p.Name, p.Securebits)
+	}
+	switch p.Bounding {
+	case BoundingKeep, BoundingDrop, BoundingRequiredOnly:
+	default:
+		return fmt.Errorf("profile %s: unknown bounding policy %v", p.Name, p.Bounding)
+	}
+	return nil
+}
+
+// Plan returns the capability state activating the profile on a thread
+// in state current results in. It returns an *MissingCapabilitiesError if
+// a required capability is not permitted.
+func (p *Profile) Plan(current *State) (*State, error) {
+	if err := p.Validate(); err != nil {
+		return nil, err
+	}
+	if missing := p.Required.Subtract(current.Permitted); !missing.IsEmpty() {
+		return nil, &MissingCapabilitiesError{Missing: missing, Program: executable()}
+	}
+	s := State{
+		Effective:   current.Effective.Union(p.Required).Subtract(p.Drop),
+		Permitted:   current.Permitted.Subtract(p.Drop),
+		Inheritable: current.Inheritable.Subtract(p.Drop),
+		Bounding:    current.Bounding,
+		Ambient:     current.Ambient.Subtract(p.Drop),
+	}
+	switch p.Bounding {
+	case BoundingDrop:
+		s.Bounding = s.Bounding.Subtract(p.Drop)
+	case BoundingRequiredOnly:
+		s.Bounding = s.Bounding.Intersect(p.Required)
+	}
+	return &s, nil
+}
+
+// Activate applies the profile to every thread: the bounding set is
+// reduced and the securebits are set while CAP_SETPCAP may still be
+// held, then the capability sets are changed as computed by Plan, which
+// lowers the dropped ambient capabilities, and finally no_new_privs is
+// set. The plan is made from the state of the calling thread. Reducing
+// the bounding set and setting securebits require CAP_SETPCAP in the
+// effective set; if a step needs it and it is missing, Activate fails
+// with an error matching unix.EPERM before anything is changed.
+//
+// Every step is applied to all threads with syscall.AllThreadsSyscall,
+// which is not available in programs built with cgo; in that case an
+// error matching unix.ENOTSUP is returned before anything is changed.
+func (p *Profile) Activate() error {
+	return withDefault(func(c *Capabilities) error {
+		current, err := c.GetState(0)
+		if err != nil {
+			return err
+		}
+		s, err := p.Plan(current)
+		if err != nil {
+			return err
+		}
+		needSetpcap := !current.Bounding.Subtract(s.Bounding).IsEmpty() || p.Securebits != 0 || p.LockSecurebits
+		if needSetpcap && !current.Effective.Contains(CapSetpcap) {
+			return fmt.Errorf("profile %s: CAP_SETPCAP not in effective set: %w", p.Name, unix.EPERM)
+		}
+		if err := c.allThreadsDropBounding(current.Bounding.Subtract(s.Bounding)); err != nil {
+			return err
+		}
+		if p.Securebits != 0 || p.LockSecurebits {
+			bits, err := GetSecurebits()
+			if err != nil {
+				return err
+			}
+			if p.Securebits != 0 {
+				bits = p.Securebits
+			}
+			if p.LockSecurebits {
+				bits = bits.WithLocks()
+			}
+			if err := SetSecurebitsAllThreads(bits); err != nil {
+				return err
+			}
+		}
+		// The kernel removes ambient capabilities that are no longer
+		// permitted and inheritable.
+		if err := allThreadsCapset(s); err != nil {
+			return newError("capset", 0, err)
+		}
+		if p.NoNewPrivs {
+			return SetNoNewPrivsAllThreads()
+		}
+		return nil
+	})
+}
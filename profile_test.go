@@ -0,0 +1,153 @@
+package capabilities
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestProfileValidate(t *testing.T) {
+	tests := []struct {
+		name    string
+		profile Profile
+		wantErr bool
+	}{
+		{
+			name: "empty",
+		},
+		{
+			name: "valid",
+			profile: Profile{
+				Required:       NewCapSet(CapNetBindService),
+				Drop:           NewCapSet(CapSysAdmin),
+				Securebits:     SecbitNoroot | SecbitNorootLocked,
+				LockSecurebits: true,
+				Bounding:       BoundingRequiredOnly,
+			},
+		},
+		{
+			name: "required and dropped",
+			profile: Profile{
+				Required: NewCapSet(CapNetBindService, CapNetRaw),
+				Drop:     NewCapSet(CapNetRaw),
+			},
+			wantErr: true,
+		},
+		{
+			name:    "unknown securebits",
+			profile: Profile{Securebits: 1 << 12},
+			wantErr: true,
+		},
+		{
+			name:    "unknown bounding policy",
+			profile: Profile{Bounding: BoundingPolicy(7)},
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.profile.Validate()
+			if (err != nil) != tt.wantErr {
+				t.Errorf("Validate = %v, want error %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestProfilePlan(t *testing.T) {
+	current := &State{
+		Effective:   NewCapSet(CapNetRaw),
+		Permitted:   NewCapSet(CapNetBindService, CapNetRaw, CapSysAdmin),
+		Inheritable: NewCapSet(CapNetRaw, CapSysAdmin),
+		Bounding:    NewCapSet(CapNetBindService, CapNetRaw, CapSysAdmin, CapSysModule),
+		Ambient:     NewCapSet(CapSysAdmin),
+	}
+	tests := []struct {
+		name    string
+		profile Profile
+		want    *State
+		missing CapSet
+		wantErr bool
+	}{
+		{
+			name: "raise required",
+			profile: Profile{
+				Required: NewCapSet(CapNetBindService),
+			},
+			want: &State{
+				Effective:   NewCapSet(CapNetBindService, CapNetRaw),
+				Permitted:   current.Permitted,
+				Inheritable: current.Inheritable,
+				Bounding:    current.Bounding,
+				Ambient:     current.Ambient,
+			},
+		},
+		{
+			name: "drop",
+			profile: Profile{
+				Required: NewCapSet(CapNetBindService),
+				Drop:     NewCapSet(CapSysAdmin, CapSysModule),
+				Bounding: BoundingDrop,
+			},
+			want: &State{
+				Effective:   NewCapSet(CapNetBindService, CapNetRaw),
+				Permitted:   NewCapSet(CapNetBindService, CapNetRaw),
+				Inheritable: NewCapSet(CapNetRaw),
+				Bounding:    NewCapSet(CapNetBindService, CapNetRaw),
+			},
+		},
+		{
+			name: "required only bounding",
+			profile: Profile{
+				Required: NewCapSet(CapNetBindService),
+				Bounding: BoundingRequiredOnly,
+			},
+			want: &State{
+				Effective:   NewCapSet(CapNetBindService, CapNetRaw),
+				Permitted:   current.Permitted,
+				Inheritable: current.Inheritable,
+				Bounding:    NewCapSet(CapNetBindService),
+				Ambient:     current.Ambient,
+			},
+		},
+		{
+			name: "missing required",
+			profile: Profile{
+				Required: NewCapSet(CapNetBindService, CapSysTime),
+			},
+			missing: NewCapSet(CapSysTime),
+			wantErr: true,
+		},
+		{
+			name: "invalid",
+			profile: Profile{
+				Required: NewCapSet(CapNetRaw),
+				Drop:     NewCapSet(CapNetRaw),
+			},
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := tt.profile.Plan(current)
+			if tt.wantErr {
+				if err == nil {
+					t.Fatalf("Plan = %v, want error", got)
+				}
+				var missing *MissingCapabilitiesError
+				if errors.As(err, &missing) != !tt.missing.IsEmpty() {
+					t.Fatalf("Plan error = %v", err)
+				}
+				if missing != nil && missing.Missing != tt.missing {
+					t.Errorf("missing = %v, want %v", missing.Missing, tt.missing)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if *got != *tt.want {
+				t.Errorf("Plan = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,96 @@
+package capabilities
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+)
+
+// profileConfig is the configuration file form of Profile.
+type profileConfig struct {
+	Name           string   `json:"name" yaml:"name"`
+	Required       []Cap    `json:"required" yaml:"required"`
+	Drop           []Cap    `json:"drop" yaml:"drop"`
+	Securebits     []string `json:"securebits" yaml:"securebits"`
+	LockSecurebits bool     `json:"lock_securebits" yaml:"lock_securebits"`
+	NoNewPrivs     bool     `json:"no_new_privs" yaml:"no_new_privs"`
+	Bounding       string   `json:"bounding" yaml:"bounding"`
+}
+
+// ParseProfile decodes a profile with unmarshal and validates it. A nil
+// unmarshal reads JSON; pass yaml.Unmarshal to read YAML. Capabilities
+// and securebits are listed by name, and the bounding policy is "keep"
+// (the default), "drop" or "required-only":
+//
+//	{
+//	  "name": "web",
+//	  "required": ["cap_net_bind_service"],
+//	  "drop": ["cap_sys_admin", "cap_sys_module"],
+//	  "securebits": ["noroot", "no_setuid_fixup"],
+//	  "lock_securebits": true,
+//	  "no_new_privs": true,
+//	  "bounding": "drop"
+//	}
+func ParseProfile(data []byte, unmarshal UnmarshalFunc) (*Profile, error) {
+	if unmarshal == nil {
+		unmarshal = json.Unmarshal
+	}
+	var cfg profileConfig
+	if err := unmarshal(data, &cfg); err != nil {
+		return nil, err
+	}
+	p := &Profile{
+		Name:           cfg.Name,
+		LockSecurebits: cfg.LockSecurebits,
+		NoNewPrivs:     cfg.NoNewPrivs,
+		Required:       NewCapSet(cfg.Required...),
+		Drop:           NewCapSet(cfg.Drop...),
+	}
+	for _, name := range cfg.Securebits {
+		bit, ok := parseSecurebit(name)
+		if !ok {
+			return nil, fmt.Errorf("profile %s: unknown securebit %q", cfg.Name, name)
+		}
+		p.Securebits |= bit
+	}
+	switch strings.ToLower(cfg.Bounding) {
+	case "", "keep":
+		p.Bounding = BoundingKeep
+	case "drop":
+		p.Bounding = BoundingDrop
+	case "required-only":
+		p.Bounding = BoundingRequiredOnly
+	default:
+		return nil, fmt.Errorf("profile %s: unknown bounding policy %q", cfg.Name, cfg.Bounding)
+	}
+	if err := p.Validate(); err != nil {
+		return nil, err
+	}
+	return p, nil
+}
+
+// LoadProfile reads the profile in the file at path with ParseProfile.
+func LoadProfile(path string, unmarshal UnmarshalFunc) (*Profile, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	p, err := ParseProfile(data, unmarshal)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	return p, nil
+}
+
+// parseSecurebit returns the securebit named name, e.g. "noroot" or
+// "SECBIT_NOROOT".
+func parseSecurebit(name string) (Securebits, bool) {
+	name = strings.TrimPrefix(strings.ToLower(strings.TrimSpace(name)), "secbit_")
+	for i, n := range securebitNames {
+		if n == name {
+			return 1 << uint(i), true
+		}
+	}
+	return 0, false
+}
@@ -0,0 +1,88 @@
+package capabilities
+
+import (
+	"testing"
+)
+
+func TestParseProfile(t *testing.T) {
+	tests := []struct {
+		name    string
+		data    string
+		want    *Profile
+		wantErr bool
+	}{
+		{
+			name: "full",
+			data: `{
+				"name": "web",
+				"required": ["cap_net_bind_service"],
+				"drop": ["CAP_SYS_ADMIN", "sys_module"],
+				"securebits": ["noroot", "SECBIT_NO_SETUID_FIXUP"],
+				"lock_securebits": true,
+				"no_new_privs": true,
+				"bounding": "drop"
+			}`,
+			want: &Profile{
+				Name:           "web",
+				Required:       NewCapSet(CapNetBindService),
+				Drop:           NewCapSet(CapSysAdmin, CapSysModule),
+				Securebits:     SecbitNoroot | SecbitNoSetuidFixup,
+				LockSecurebits: true,
+				NoNewPrivs:     true,
+				Bounding:       BoundingDrop,
+			},
+		},
+		{
+			name: "defaults",
+			data: `{"name": "min"}`,
+			want: &Profile{Name: "min", Bounding: BoundingKeep},
+		},
+		{
+			name: "required only",
+			data: `{"required": ["net_bind_service"], "bounding": "Required-Only"}`,
+			want: &Profile{Required: NewCapSet(CapNetBindService), Bounding: BoundingRequiredOnly},
+		},
+		{
+			name:    "unknown capability",
+			data:    `{"required": ["cap_bogus"]}`,
+			wantErr: true,
+		},
+		{
+			name:    "unknown securebit",
+			data:    `{"securebits": ["bogus"]}`,
+			wantErr: true,
+		},
+		{
+			name:    "unknown bounding policy",
+			data:    `{"bounding": "some"}`,
+			wantErr: true,
+		},
+		{
+			name:    "contradiction",
+			data:    `{"required": ["cap_net_raw"], "drop": ["cap_net_raw"]}`,
+			wantErr: true,
+		},
+		{
+			name:    "malformed",
+			data:    `{"name": `,
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ParseProfile([]byte(tt.data), nil)
+			if tt.wantErr {
+				if err == nil {
+					t.Fatalf("ParseProfile = %+v, want error", got)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if *got != *tt.want {
+				t.Errorf("ParseProfile = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,57 @@
+package capabilities
+
+import (
+	"fmt"
+	"io"
+	"text/tabwriter"
+)
+
+// HasDefaultCaps returns true if the capabilities of p are what is
+// expected for its effective user ID: every capability for root and none
+// for other users. Such processes are uninteresting to an audit.
+func (p *Process) HasDefaultCaps() bool {
+	s := &p.State
+	if p.EUID == 0 {
+		all := AllCaps()
+		return s.Effective == all && s.Permitted == all
+	}
+	return s.Effective.IsEmpty() && s.Permitted.IsEmpty() && s.Ambient.IsEmpty()
+}
+
+// NonDefaultProcesses returns the processes whose capabilities differ
+// from the default for their user ID, like pscap(8) from libcap-ng: root
+// processes that dropped capabilities and non-root processes that hold
+// some. Processes that cannot be read are skipped.
+func (c *Capabilities) NonDefaultProcesses() ([]Process, error) {
+	procs, err := c.ScanProcesses(ProcessFilter{})
+	if err != nil {
+		return nil, err
+	}
+	var found []Process
+	for _, p := range procs {
+		if !p.HasDefaultCaps() {
+			found = append(found, p)
+		}
+	}
+	return found, nil
+}
+
+// WritePscap writes procs as a table in the style of pscap(8), listing
+// the effective capabilities of each process and marking processes
+// whose permitted set holds more than the effective set with " +":
+//
+//	PPID  PID  UID   NAME     CAPABILITIES
+//	1     812  0     sshd     cap_chown,cap_setgid,cap_setuid +
+//	1     920  1000  ping     cap_net_raw
+func WritePscap(w io.Writer, procs []Process) error {
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(tw, "PPID\tPID\tUID\tNAME\tCAPABILITIES")
+	for _, p := range procs {
+		caps := summarizeSet(p.State.Effective)
+		if !p.State.Permitted.Subtract(p.State.Effective).IsEmpty() {
+			caps += " +"
+		}
+		fmt.Fprintf(tw, "%d\t%d\t%d\t%s\t%s\n", p.PPid, p.Pid, p.EUID, p.Name, caps)
+	}
+	return tw.Flush()
+}
@@ -0,0 +1,52 @@
+package capabilities
+
+import (
+	"os"
+)
+
+// Require returns an *MissingCapabilitiesError naming every capability of
+// caps that is not in the effective set of the calling thread, with a
+// setcap(8) command and a systemd directive that grant them. Privileged
+// daemons call it at startup to fail with one actionable message rather
+// than an EPERM deep inside the first privileged operation.
+func Require(caps ...Cap) error {
+	return require(NewCapSet(caps...), Effective)
+}
+
+// RequirePermitted is like Require but checks the permitted set, for
+// programs that raise capabilities in the effective set only while they
+// use them.
+func RequirePermitted(caps ...Cap) error {
+	return require(NewCapSet(caps...), Permitted)
+}
+
+func require(caps CapSet, set CapabilitySet) error {
+	var s *State
+	err := withDefault(func(c *Capabilities) error {
+		var err error
+		s, err = c.GetState(0)
+		return err
+	})
+	if err != nil {
+		return err
+	}
+	missing := caps.Subtract(s.Set(set))
+	if missing.IsEmpty() {
+		return nil
+	}
+	e := &MissingCapabilitiesError{Missing: missing}
+	if set == Effective {
+		e.Raisable = missing.Intersect(s.Permitted)
+	}
+	e.Program = executable()
+	return e
+}
+
+// executable returns the path of the executable of the calling process.
+func executable() string {
+	path, err := os.Executable()
+	if err != nil {
+		return os.Args[0]
+	}
+	return path
+}
@@ -0,0 +1,167 @@
+package capabilities
+
+import (
+	"fmt"
+	"sort"
+)
+
+// RiskLevel rates how much power a capability or set of capabilities
+// gives a process.
+type RiskLevel int
+
+const (
+	// RiskNone is the level of an empty set.
+	RiskNone RiskLevel = iota
+	// RiskLow capabilities grant narrow, mostly harmless privileges.
+	RiskLow
+	// RiskMedium capabilities allow denial of service or observing
+	// other processes.
+	RiskMedium
+	// RiskHigh capabilities allow tampering with the system or other
+	// processes.
+	RiskHigh
+	// RiskCritical capabilities are equivalent to full root, or can be
+	// turned into it.
+	RiskCritical
+)
+
+// String returns "none", "low", "medium", "high" or "critical".
+func (r RiskLevel) String() string {
+	switch r {
+	case RiskNone:
+		return "none"
+	case RiskLow:
+		return "low"
+	case RiskMedium:
+		return "medium"
+	case RiskHigh:
+		return "high"
+	case RiskCritical:
+		return "critical"
+	}
+	return fmt.Sprintf("RiskLevel(%d)", int(r))
+}
+
+// capRisks rates each capability. Capabilities missing from the table,
+// such as ones added by newer kernels, are rated RiskHigh.
+var capRisks = map[Cap]RiskLevel{
+	CapChown:             RiskCritical,
+	CapDacOverride:       RiskCritical,
+	CapDacReadSearch:     RiskCritical,
+	CapFowner:            RiskCritical,
+	CapFsetid:            RiskHigh,
+	CapKill:              RiskHigh,
+	CapSetgid:            RiskCritical,
+	CapSetuid:            RiskCritical,
+	CapSetpcap:           RiskHigh,
+	CapLinuxImmutable:    RiskHigh,
+	CapNetBindService:    RiskLow,
+	CapNetBroadcast:      RiskLow,
+	CapNetAdmin:          RiskHigh,
+	CapNetRaw:            RiskMedium,
+	CapIpcLock:           RiskMedium,
+	CapIpcOwner:          RiskHigh,
+	CapSysModule:         RiskCritical,
+	CapSysRawio:          RiskCritical,
+	CapSysChroot:         RiskHigh,
+	CapSysPtrace:         RiskCritical,
+	CapSysPacct:          RiskMedium,
+	CapSysAdmin:          RiskCritical,
+	CapSysBoot:           RiskHigh,
+	CapSysNice:           RiskMedium,
+	CapSysResource:       RiskMedium,
+	CapSysTime:           RiskHigh,
+	CapSysTtyConfig:      RiskMedium,
+	CapMknod:             RiskHigh,
+	CapLease:             RiskMedium,
+	CapAuditWrite:        RiskLow,
+	CapAuditControl:      RiskHigh,
+	CapSetfcap:           RiskCritical,
+	CapMacOverride:       RiskCritical,
+	CapMacAdmin:          RiskCritical,
+	CapSyslog:            RiskHigh,
+	CapWakeAlarm:         RiskMedium,
+	CapBlockSuspend:      RiskMedium,
+	CapAuditRead:         RiskMedium,
+	CapPerfmon:           RiskHigh,
+	CapBpf:               RiskCritical,
+	CapCheckpointRestore: RiskHigh,
+}
+
+// riskWeights are the score contributions of each level.
+var riskWeights = [...]int{RiskNone: 0, RiskLow: 1, RiskMedium: 3, RiskHigh: 10, RiskCritical: 50}
+
+// CapRisk returns the risk level of c, e.g. RiskCritical for
+// CAP_SYS_ADMIN and RiskLow for CAP_NET_BIND_SERVICE.
+func CapRisk(c Cap) RiskLevel {
+	if r, ok := capRisks[c]; ok {
+		return r
+	}
+	return RiskHigh
+}
+
+// Risk is the risk assessment of a capability set.
+type Risk struct {
+	// Level is the highest level of any capability in the set.
+	Level RiskLevel
+	// Score sums a weight per capability that grows steeply with its
+	// level, so one critical capability outweighs many low ones. It
+	// orders sets of the same level.
+	Score int
+	// Critical and High are the capabilities at those levels.
+	Critical CapSet
+	High     CapSet
+}
+
+// String summarizes the assessment, e.g.
+// "critical (score 100): cap_sys_ptrace,cap_sys_admin".
+func (r Risk) String() string {
+	switch {
+	case !r.Critical.IsEmpty():
+		return fmt.Sprintf("%v (score %d): %s", r.Level, r.Score, FormatCapList(r.Critical))
+	case !r.High.IsEmpty():
+		return fmt.Sprintf("%v (score %d): %s", r.Level, r.Score, FormatCapList(r.High))
+	}
+	return fmt.Sprintf("%v (score %d)", r.Level, r.Score)
+}
+
+// AssessRisk rates caps by the capabilities it holds.
+func AssessRisk(caps CapSet) Risk {
+	var r Risk
+	for _, c := range caps.Caps() {
+		level := CapRisk(c)
+		if level > r.Level {
+			r.Level = level
+		}
+		r.Score += riskWeights[level]
+		switch level {
+		case RiskCritical:
+			r.Critical = r.Critical.Add(c)
+		case RiskHigh:
+			r.High = r.High.Add(c)
+		}
+	}
+	return r
+}
+
+// Risk rates the capabilities the process can use: those in its
+// effective, permitted and ambient sets.
+func (p *Process) Risk() Risk {
+	return AssessRisk(p.State.Effective.Union(p.State.Permitted).Union(p.State.Ambient))
+}
+
+// SortByRisk sorts procs by Process.Risk, worst first, and by pid among
+// processes with the same score.
+func SortByRisk(procs []Process) {
+	scores := make(map[int]int, len(procs))
+	for i := range procs {
+		scores[procs[i].Pid] = procs[i].Risk().Score
+	}
+	sort.SliceStable(procs, func(i, j int) bool {
+		si, sj := scores[procs[i].Pid], scores[procs[j].Pid]
+		if si != sj {
+			return si > sj
+		}
+		return procs[i].Pid < procs[j].Pid
+	})
+}
@@ -0,0 +1,55 @@
+package capabilities
+
+import (
+	"testing"
+)
+
+func TestAssessRisk(t *testing.T) {
+	tests := []struct {
+		name string
+		caps CapSet
+		want Risk
+	}{
+		{
+			name: "empty",
+			want: Risk{Level: RiskNone},
+		},
+		{
+			name: "low",
+			caps: NewCapSet(CapNetBindService, CapAuditWrite),
+			want: Risk{Level: RiskLow, Score: 2},
+		},
+		{
+			name: "medium",
+			caps: NewCapSet(CapNetBindService, CapNetRaw),
+			want: Risk{Level: RiskMedium, Score: 4},
+		},
+		{
+			name: "high",
+			caps: NewCapSet(CapNetAdmin, CapNetRaw),
+			want: Risk{Level: RiskHigh, Score: 13, High: NewCapSet(CapNetAdmin)},
+		},
+		{
+			name: "critical",
+			caps: NewCapSet(CapSysAdmin, CapSysPtrace, CapKill),
+			want: Risk{
+				Level:    RiskCritical,
+				Score:    110,
+				Critical: NewCapSet(CapSysAdmin, CapSysPtrace),
+				High:     NewCapSet(CapKill),
+			},
+		},
+		{
+			name: "unknown capability",
+			caps: NewCapSet(Cap(63)),
+			want: Risk{Level: RiskHigh, Score: 10, High: NewCapSet(Cap(63))},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := AssessRisk(tt.caps); got != tt.want {
+				t.Errorf("AssessRisk = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
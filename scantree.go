@@ -0,0 +1,169 @@
+package capabilities
+
+import (
+	"errors"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"syscall"
+
+	"golang.org/x/sys/unix"
+)
+
+// PrivilegedFile is a file found by ScanTree.
+type PrivilegedFile struct {
+	Path string
+	// Caps holds the file capabilities, or nil if the file has none.
+	Caps *FileCaps
+	// Mode, UID and GID are the permission bits and owner of the file.
+	Mode fs.FileMode
+	UID  uint32
+	GID  uint32
+}
+
+// Setuid returns true if the file is set-user-ID.
+func (f *PrivilegedFile) Setuid() bool {
+	return f.Mode&fs.ModeSetuid != 0
+}
+
+// Setgid returns true if the file is set-group-ID.
+func (f *PrivilegedFile) Setgid() bool {
+	return f.Mode&fs.ModeSetgid != 0
+}
+
+// SetIDWithCaps returns true for the dangerous combination of a
+// set-user-ID or set-group-ID file that also carries capabilities.
+func (f *PrivilegedFile) SetIDWithCaps() bool {
+	return (f.Setuid() || f.Setgid()) && f.Caps != nil
+}
+
+// ScanOption configures ScanTree.
+type ScanOption func(*scanOptions)
+
+type scanOptions struct {
+	crossDevice bool
+	setID       bool
+	dryRun      bool
+	onError     func(path string, err error) error
+}
+
+// WithSetID makes ScanTree also report set-user-ID and set-group-ID
+// files, producing a single inventory of privileged binaries.
+func WithSetID() ScanOption {
+	return func(o *scanOptions) {
+		o.setID = true
+	}
+}
+
+// WithCrossDevice makes ScanTree descend into directories on other file
+// systems than root. By default the scan stays on the file system of
+// root, like find -xdev.
+func WithCrossDevice() ScanOption {
+	return func(o *scanOptions) {
+		o.crossDevice = true
+	}
+}
+
+// WithErrorHandler sets a function called for every path that cannot be
+// read. Returning nil continues the scan; returning an error stops it and
+// ScanTree returns that error. By default unreadable paths are skipped.
+func WithErrorHandler(fn func(path string, err error) error) ScanOption {
+	return func(o *scanOptions) {
+		o.onError = fn
+	}
+}
+
+// ScanTree walks the directory tree rooted at root and returns every
+// regular file carrying a security.capability extended attribute, with
+// its decoded capabilities. With WithSetID, set-user-ID and set-group-ID
+// files are reported as well. Symbolic links are not followed.
+func ScanTree(root string, opts ...ScanOption) ([]PrivilegedFile, error) {
+	o := scanOptions{
+		onError: func(string, error) error { return nil },
+	}
+	for _, opt := range opts {
+		opt(&o)
+	}
+	var found []PrivilegedFile
+	err := walkTree(root, &o, func(path string) error {
+		f, err := inspectFile(path)
+		if err != nil {
+			return err
+		}
+		if f.Caps != nil || (o.setID && (f.Setuid() || f.Setgid())) {
+			found = append(found, *f)
+		}
+		return nil
+	})
+	return found, err
+}
+
+// walkTree calls fn for every regular file below root, honouring the
+// cross-device and error handling options. Errors returned by fn are
+// passed to the error handler.
+func walkTree(root string, o *scanOptions, fn func(path string) error) error {
+	rootDev, err := deviceOf(root)
+	if err != nil {
+		return err
+	}
+	return filepath.WalkDir(root, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			if handlerErr := o.onError(path, err); handlerErr != nil {
+				return handlerErr
+			}
+			if d != nil && d.IsDir() {
+				return fs.SkipDir
+			}
+			return nil
+		}
+		if d.IsDir() {
+			if !o.crossDevice && path != root {
+				dev, err := deviceOf(path)
+				if err != nil {
+					return o.onError(path, err)
+				}
+				if dev != rootDev {
+					return fs.SkipDir
+				}
+			}
+			return nil
+		}
+		if !d.Type().IsRegular() {
+			return nil
+		}
+		if err := fn(path); err != nil {
+			return o.onError(path, err)
+		}
+		return nil
+	})
+}
+
+// inspectFile returns the capabilities, mode and owner of path.
+func inspectFile(path string) (*PrivilegedFile, error) {
+	info, err := os.Lstat(path)
+	if err != nil {
+		return nil, err
+	}
+	f := &PrivilegedFile{Path: path, Mode: info.Mode()}
+	if st, ok := info.Sys().(*syscall.Stat_t); ok {
+		f.UID = st.Uid
+		f.GID = st.Gid
+	}
+	fc, err := GetFileCaps(path)
+	switch {
+	case err == nil:
+		f.Caps = fc
+	case !errors.Is(err, ErrNoFileCaps):
+		return nil, err
+	}
+	return f, nil
+}
+
+// deviceOf returns the device number of the file system holding path.
+func deviceOf(path string) (uint64, error) {
+	var st unix.Stat_t
+	if err := unix.Lstat(path, &st); err != nil {
+		return 0, &os.PathError{Op: "lstat", Path: path, Err: err}
+	}
+	return st.Dev, nil
+}
@@ -0,0 +1,171 @@
+package capabilities
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+// SeccompMode is the seccomp mode of a process, as reported in the
+// Seccomp field of /proc/<pid>/status.
+type SeccompMode int
+
+const (
+	// SeccompDisabled means the process is not confined by seccomp.
+	SeccompDisabled SeccompMode = 0
+	// SeccompStrict means only read, write, _exit and sigreturn are
+	// allowed.
+	SeccompStrict SeccompMode = 1
+	// SeccompFilter means the process is confined by BPF filters.
+	SeccompFilter SeccompMode = 2
+)
+
+// String returns "disabled", "strict" or "filter".
+func (m SeccompMode) String() string {
+	switch m {
+	case SeccompDisabled:
+		return "disabled"
+	case SeccompStrict:
+		return "strict"
+	case SeccompFilter:
+		return "filter"
+	}
+	return fmt.Sprintf("SeccompMode(%d)", int(m))
+}
+
+// SecurityContext is the complete credential picture of a process: its
+// user and group IDs, no_new_privs, seccomp mode, securebits, user
+// namespace and all five capability sets.
+type SecurityContext struct {
+	Pid int
+	// Real, effective, saved set and file system user and group IDs.
+	UID, EUID, SUID, FSUID int
+	GID, EGID, SGID, FSGID int
+	// Groups are the supplementary group IDs.
+	Groups []int
+	// NoNewPrivs is the no_new_privs attribute (Linux 4.10 and later
+	// report it for other processes).
+	NoNewPrivs bool
+	// Seccomp is the seccomp mode.
+	Seccomp SeccompMode
+	// Securebits holds the securebits flags. They are read with prctl
+	// for the calling process and from the SecBits status field for
+	// others; mainline kernels do not have that field, in which case
+	// HaveSecurebits is false.
+	Securebits     Securebits
+	HaveSecurebits bool
+	// KeepCaps is the keep-capabilities flag (PR_GET_KEEPCAPS), valid
+	// when HaveSecurebits is true.
+	KeepCaps bool
+	// InUserNS is true if the process runs in a user namespace other
+	// than the initial one, where its capabilities only reach resources
+	// owned by that namespace. UserNS describes the namespace, or is nil
+	// if it could not be read.
+	InUserNS bool
+	UserNS   *UserNamespace
+	// State holds the capability sets.
+	State State
+}
+
+// SecurityContext reads the security context of pid from
+// /proc/<pid>/status. A pid of 0 refers to the calling thread.
+// Securebits are included when pid refers to the calling process or
+// the kernel reports them in the status file.
+func (c *Capabilities) SecurityContext(pid int) (*SecurityContext, error) {
+	path := c.procPath(pid, "status")
+	if pid == 0 {
+		// The calling thread, consistent with the securebits read
+		// with prctl below.
+		path = filepath.Join(defaultProcRoot, "thread-self", "status")
+	}
+	status, err := os.ReadFile(path)
+	if err != nil {
+		return nil, newError("read status", pid, err)
+	}
+	s, err := ParseProcStatus(bytes.NewReader(status))
+	if err != nil {
+		return nil, newError("read status", pid, err)
+	}
+	sc := &SecurityContext{Pid: pid, State: *s}
+	if pid == 0 {
+		sc.Pid = os.Getpid()
+	}
+	scanner := bufio.NewScanner(bytes.NewReader(status))
+	for scanner.Scan() {
+		key, value, ok := cut(scanner.Text(), ":")
+		if !ok {
+			continue
+		}
+		fields := strings.Fields(value)
+		switch key {
+		case "Uid":
+			parseIDs(fields, &sc.UID, &sc.EUID, &sc.SUID, &sc.FSUID)
+		case "Gid":
+			parseIDs(fields, &sc.GID, &sc.EGID, &sc.SGID, &sc.FSGID)
+		case "Groups":
+			for _, f := range fields {
+				if g, err := strconv.Atoi(f); err == nil {
+					sc.Groups = append(sc.Groups, g)
+				}
+			}
+		case "NoNewPrivs":
+			sc.NoNewPrivs = parseFlag(fields)
+		case "Seccomp":
+			sc.Seccomp = parseSeccomp(fields)
+		case "SecBits":
+			sc.Securebits, sc.HaveSecurebits = parseSecurebits(fields)
+		}
+	}
+	if c.isSelf(pid) {
+		if bits, err := GetSecurebits(); err == nil {
+			sc.Securebits = bits
+			sc.HaveSecurebits = true
+		}
+	}
+	sc.KeepCaps = sc.Securebits&SecbitKeepCaps != 0
+	if ns, err := c.UserNamespace(pid); err == nil {
+		sc.UserNS = ns
+		sc.InUserNS = !ns.Initial
+	}
+	return sc, nil
+}
+
+// parseFlag parses a 0 or 1 status field such as NoNewPrivs.
+func parseFlag(fields []string) bool {
+	return len(fields) > 0 && fields[0] == "1"
+}
+
+// parseSeccomp parses the Seccomp status field.
+func parseSeccomp(fields []string) SeccompMode {
+	if len(fields) == 0 {
+		return SeccompDisabled
+	}
+	mode, _ := strconv.Atoi(fields[0])
+	return SeccompMode(mode)
+}
+
+// parseSecurebits parses a hexadecimal SecBits status field.
+func parseSecurebits(fields []string) (Securebits, bool) {
+	if len(fields) == 0 {
+		return 0, false
+	}
+	bits, err := strconv.ParseUint(fields[0], 16, 32)
+	if err != nil {
+		return 0, false
+	}
+	return Securebits(bits), true
+}
+
+// parseIDs parses the ID columns of a Uid or Gid status line into ids.
+func parseIDs(fields []string, ids ...*int) {
+	for i, f := range fields {
+		if i >= len(ids) {
+			break
+		}
+		*ids[i], _ = strconv.Atoi(f)
+	}
+}
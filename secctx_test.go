@@ -0,0 +1,29 @@
+package capabilities
+
+import (
+	"testing"
+)
+
+func TestParseSecurebits(t *testing.T) {
+	tests := []struct {
+		name   string
+		fields []string
+		want   Securebits
+		ok     bool
+	}{
+		{"none", []string{"00000000"}, 0, true},
+		{"noroot locked", []string{"00000003"}, SecbitNoroot | SecbitNorootLocked, true},
+		{"all", []string{"000000ff"}, securebitFlags | securebitFlags<<1, true},
+		{"short", []string{"2f"}, SecbitNoroot | SecbitNorootLocked | SecbitNoSetuidFixup | SecbitNoSetuidFixupLocked | SecbitKeepCapsLocked, true},
+		{"missing", nil, 0, false},
+		{"malformed", []string{"xyz"}, 0, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, ok := parseSecurebits(tt.fields)
+			if got != tt.want || ok != tt.ok {
+				t.Errorf("parseSecurebits(%q) = %v, %v, want %v, %v", tt.fields, got, ok, tt.want, tt.ok)
+			}
+		})
+	}
+}
@@ -0,0 +1,146 @@
+package capabilities
+
+import (
+	"fmt"
+	"strings"
+
+	"golang.org/x/sys/unix"
+)
+
+// Securebits holds the securebits flags of a thread, which control how
+// the kernel grants and removes capabilities for root and on changes of
+// user ID. See capabilities(7) and linux/securebits.h.
+type Securebits uint32
+
+const (
+	// SecbitNoroot stops the kernel from granting capabilities when a
+	// set-user-ID root program is executed or a root process calls
+	// execve(2).
+	SecbitNoroot Securebits = 1 << 0
+	// SecbitNorootLocked locks SecbitNoroot.
+	SecbitNorootLocked Securebits = 1 << 1
+	// SecbitNoSetuidFixup stops the kernel from adjusting the permitted,
+	// effective and ambient sets when the user IDs change between zero
+	// and nonzero.
+	SecbitNoSetuidFixup Securebits = 1 << 2
+	// SecbitNoSetuidFixupLocked locks SecbitNoSetuidFixup.
+	SecbitNoSetuidFixupLocked Securebits = 1 << 3
+	// SecbitKeepCaps keeps the permitted set when all user IDs change
+	// from zero to nonzero. It is cleared on execve(2).
+	SecbitKeepCaps Securebits = 1 << 4
+	// SecbitKeepCapsLocked locks SecbitKeepCaps.
+	SecbitKeepCapsLocked Securebits = 1 << 5
+	// SecbitNoCapAmbientRaise prevents raising ambient capabilities.
+	SecbitNoCapAmbientRaise Securebits = 1 << 6
+	// SecbitNoCapAmbientRaiseLocked locks SecbitNoCapAmbientRaise.
+	SecbitNoCapAmbientRaiseLocked Securebits = 1 << 7
+)
+
+var securebitNames = [...]string{
+	"noroot",
+	"noroot_locked",
+	"no_setuid_fixup",
+	"no_setuid_fixup_locked",
+	"keep_caps",
+	"keep_caps_locked",
+	"no_cap_ambient_raise",
+	"no_cap_ambient_raise_locked",
+}
+
+// String returns the names of the flags that are set separated by
+// commas, e.g. "noroot,noroot_locked", or "none".
+func (b Securebits) String() string {
+	var names []string
+	for i, name := range securebitNames {
+		if b&(1<<uint(i)) != 0 {
+			names = append(names, name)
+		}
+	}
+	if unknown := b &^ (1<<uint(len(securebitNames)) - 1); unknown != 0 {
+		names = append(names, fmt.Sprintf("0x%x", uint32(unknown)))
+	}
+	if len(names) == 0 {
+		return "none"
+	}
+	return strings.Join(names, ",")
+}
+
+// GetSecurebits returns the securebits of the calling thread with
+// prctl(PR_GET_SECUREBITS).
+func GetSecurebits() (Securebits, error) {
+	bits, err := unix.PrctlRetInt(unix.PR_GET_SECUREBITS, 0, 0, 0, 0)
+	if err != nil {
+		return 0, newProbeError("get securebits", 0, err)
+	}
+	return Securebits(bits), nil
+}
+
+// SetSecurebits replaces the securebits of the calling thread with
+// prctl(PR_SET_SECUREBITS). Changing securebits requires CAP_SETPCAP, and
+// flags whose lock is set cannot be changed.
+//
+// Only the calling thread is changed; see Thread for what that means in
+// a Go program. SetSecurebitsAllThreads changes every thread.
+func SetSecurebits(bits Securebits) error {
+	err := unix.Prctl(unix.PR_SET_SECUREBITS, uintptr(bits), 0, 0, 0)
+	return newError("set securebits", 0, err)
+}
+
+// SetSecurebitsAllThreads replaces the securebits of every thread of the
+// process, as SetSecurebits does for the calling thread. It is not
+// available in programs built with cgo, where an error matching
+// unix.ENOTSUP is returned.
+func SetSecurebitsAllThreads(bits Securebits) error {
+	err := allThreadsPrctl(unix.PR_SET_SECUREBITS, uintptr(bits))
+	return newError("set securebits", 0, err)
+}
+
+// securebitFlags are the securebits that have a lock companion one bit
+// above them.
+const securebitFlags = SecbitNoroot | SecbitNoSetuidFixup | SecbitKeepCaps | SecbitNoCapAmbientRaise
+
+// WithLocks returns b with the lock of every set flag added, e.g.
+// SecbitNoroot|SecbitNorootLocked for SecbitNoroot.
+func (b Securebits) WithLocks() Securebits {
+	return b | (b&securebitFlags)<<1
+}
+
+// LockSecurebits sets the lock of every securebits flag currently set on
+// the calling thread, making the securebits configuration irreversible
+// for the thread and its descendants. Flags that are clear stay
+// unlocked. Like SetSecurebits it requires CAP_SETPCAP.
+func LockSecurebits() error {
+	bits, err := GetSecurebits()
+	if err != nil {
+		return err
+	}
+	if bits.WithLocks() == bits {
+		return nil
+	}
+	return SetSecurebits(bits.WithLocks())
+}
+
+// KeepCaps returns the keep-capabilities flag of the calling thread with
+// prctl(PR_GET_KEEPCAPS). It is the same flag as SecbitKeepCaps.
+func KeepCaps() (bool, error) {
+	v, err := unix.PrctlRetInt(unix.PR_GET_KEEPCAPS, 0, 0, 0, 0)
+	if err != nil {
+		return false, newError("get keepcaps", 0, err)
+	}
+	return v == 1, nil
+}
+
+// SetKeepCaps sets or clears the keep-capabilities flag of the calling
+// thread with prctl(PR_SET_KEEPCAPS). While set, the permitted set is
+// kept when all user IDs change from zero to nonzero, for callers
+// building their own user switch; DropPrivileges performs the complete
+// sequence. The flag is cleared on execve(2) and fails with EPERM if
+// SecbitKeepCapsLocked is set.
+func SetKeepCaps(keep bool) error {
+	var v uintptr
+	if keep {
+		v = 1
+	}
+	err := unix.Prctl(unix.PR_SET_KEEPCAPS, v, 0, 0, 0)
+	return newError("set keepcaps", 0, err)
+}
@@ -0,0 +1,251 @@
+package capabilities
+
+import (
+	"errors"
+	"fmt"
+
+	"golang.org/x/sys/unix"
+)
+
+// State is the complete capability state of a thread.
+type State struct {
+	Effective   CapSet
+	Permitted   CapSet
+	Inheritable CapSet
+	Bounding    CapSet
+	Ambient     CapSet
+}
+
+// Set returns the CapSet for capSet.
+func (s *State) Set(capSet CapabilitySet) CapSet {
+	switch capSet {
+	case Effective:
+		return s.Effective
+	case Permitted:
+		return s.Permitted
+	case Inheritable:
+		return s.Inheritable
+	case Bounding:
+		return s.Bounding
+	case Ambient:
+		return s.Ambient
+	}
+	return 0
+}
+
+// Validate checks the invariants the kernel enforces on a capability
+// state: effective must be a subset of permitted, ambient must be a
+// subset of both permitted and inheritable, and every capability must be
+// known to the running kernel.
+func (s *State) Validate() error {
+	all := s.Effective | s.Permitted | s.Inheritable | s.Bounding | s.Ambient
+	for _, c := range all.Caps() {
+		if !validCap(int(c)) {
+			return fmt.Errorf("capability %d (last capability is %d): %w", c, LastCap(), ErrKernelTooOld)
+		}
+	}
+	if extra := s.Effective.Subtract(s.Permitted); !extra.IsEmpty() {
+		return fmt.Errorf("effective capabilities %v not in permitted set", extra.Caps())
+	}
+	if extra := s.Ambient.Subtract(s.Permitted.Intersect(s.Inheritable)); !extra.IsEmpty() {
+		return fmt.Errorf("ambient capabilities %v not in permitted and inheritable sets", extra.Caps())
+	}
+	return nil
+}
+
+// Apply makes s the capability state of the calling thread. Capabilities
+// missing from s.Bounding are dropped from the bounding set, the
+// effective, permitted and inheritable sets are replaced and the ambient
+// set is reset to s.Ambient.
+//
+// Capabilities are a per-thread attribute. Callers should hold
+// runtime.LockOSThread while applying and relying on the state.
+func (s *State) Apply() error {
+	if err := s.Validate(); err != nil {
+		return err
+	}
+	return withDefault(func(c *Capabilities) error {
+		return c.apply(s)
+	})
+}
+
+func (c *Capabilities) apply(s *State) error {
+	if c.Version == 1 {
+		if s.Effective|s.Permitted|s.Inheritable > 0xffffffff {
+			return errors.New("capabilities above 31 not supported for capability v1")
+		}
+	}
+	// Only capabilities still in the bounding set are dropped, as
+	// PR_CAPBSET_DROP requires CAP_SETPCAP even when the capability is
+	// already gone.
+	if err := c.dropBounding(AllCaps().Subtract(s.Bounding)); err != nil {
+		return err
+	}
+	if err := c.store(s); err != nil {
+		return err
+	}
+	if c.Version == 1 {
+		return nil
+	}
+	err := unix.Prctl(unix.PR_CAP_AMBIENT, unix.PR_CAP_AMBIENT_CLEAR_ALL, 0, 0, 0)
+	if err != nil {
+		return newProbeError("clear ambient capabilities", 0, err)
+	}
+	for _, capability := range s.Ambient.Caps() {
+		err := unix.Prctl(unix.PR_CAP_AMBIENT, unix.PR_CAP_AMBIENT_RAISE, uintptr(capability), 0, 0)
+		if err != nil {
+			return newProbeError("raise ambient capability", 0, err)
+		}
+	}
+	return nil
+}
+
+// store sets the effective, permitted and inheritable sets of the calling
+// thread.
+func (c *Capabilities) store(s *State) error {
+	if c.Version == 1 {
+		c.v1.Header.Version = unix.LINUX_CAPABILITY_VERSION_1
+		c.v1.Header.Pid = 0
+		c.v1.Data.Effective = uint32(s.Effective)
+		c.v1.Data.Permitted = uint32(s.Permitted)
+		c.v1.Data.Inheritable = uint32(s.Inheritable)
+		return newError("capset", 0, unix.Capset(&c.v1.Header, &c.v1.Data))
+	}
+	c.allocV3()
+	c.v3.Header.Version, _ = headerVersion(c.Version)
+	c.v3.Header.Pid = 0
+	for i := range c.v3.Datap {
+		shift := uint(32 * i)
+		c.v3.Datap[i].Effective = uint32(s.Effective >> shift)
+		c.v3.Datap[i].Permitted = uint32(s.Permitted >> shift)
+		c.v3.Datap[i].Inheritable = uint32(s.Inheritable >> shift)
+	}
+	return newError("capset", 0, unix.Capset(&c.v3.Header, &c.v3.Datap[0]))
+}
+
+// GetState returns all five capability sets of pid. A pid of 0 refers to
+// the calling thread.
+func (c *Capabilities) GetState(pid int) (*State, error) {
+	if c.Version < 1 || c.Version > 3 {
+		return nil, errors.New("invalid capability version")
+	}
+	if err := c.load(pid); err != nil {
+		return nil, err
+	}
+	var s State
+	var err error
+	if s.Effective, err = c.collect(Effective); err != nil {
+		return nil, err
+	}
+	if s.Permitted, err = c.collect(Permitted); err != nil {
+		return nil, err
+	}
+	if s.Inheritable, err = c.collect(Inheritable); err != nil {
+		return nil, err
+	}
+	if c.Version == 1 {
+		return &s, nil
+	}
+	if err = c.loadBounding(pid); err != nil {
+		return nil, err
+	}
+	if err = c.loadAmbient(pid); err != nil {
+		return nil, err
+	}
+	if s.Bounding, err = c.collect(Bounding); err != nil {
+		return nil, err
+	}
+	if s.Ambient, err = c.collect(Ambient); err != nil {
+		return nil, err
+	}
+	return &s, nil
+}
+
+// GetState returns the capability state of the calling thread.
+func GetState() (*State, error) {
+	var s *State
+	err := withDefault(func(c *Capabilities) error {
+		var err error
+		s, err = c.GetState(0)
+		return err
+	})
+	return s, err
+}
+
+// StateBuilder constructs a State. Use NewState to create one.
+type StateBuilder struct {
+	s           State
+	hasBounding bool
+}
+
+// NewState returns a builder for a State with all sets empty. Unless
+// Bounding is called, the built state keeps every capability supported by
+// the kernel in the bounding set.
+func NewState() *StateBuilder {
+	return &StateBuilder{}
+}
+
+// Effective adds caps to the effective set.
+func (b *StateBuilder) Effective(caps ...Cap) *StateBuilder {
+	b.s.Effective = b.s.Effective.Add(caps...)
+	return b
+}
+
+// Permitted adds caps to the permitted set.
+func (b *StateBuilder) Permitted(caps ...Cap) *StateBuilder {
+	b.s.Permitted = b.s.Permitted.Add(caps...)
+	return b
+}
+
+// Inheritable adds caps to the inheritable set.
+func (b *StateBuilder) Inheritable(caps ...Cap) *StateBuilder {
+	b.s.Inheritable = b.s.Inheritable.Add(caps...)
+	return b
+}
+
+// Bounding adds caps to the bounding set.
+func (b *StateBuilder) Bounding(caps ...Cap) *StateBuilder {
+	b.s.Bounding = b.s.Bounding.Add(caps...)
+	b.hasBounding = true
+	return b
+}
+
+// Ambient adds caps to the ambient set.
+func (b *StateBuilder) Ambient(caps ...Cap) *StateBuilder {
+	b.s.Ambient = b.s.Ambient.Add(caps...)
+	return b
+}
+
+// Build validates and returns the State.
+func (b *StateBuilder) Build() (*State, error) {
+	s := b.s
+	if !b.hasBounding {
+		s.Bounding = AllCaps()
+	}
+	if err := s.Validate(); err != nil {
+		return nil, err
+	}
+	return &s, nil
+}
+
+// String returns a one line summary of all five sets, e.g.
+// "effective=cap_net_raw permitted=cap_net_raw inheritable=none
+// bounding=all ambient=none". A set holding every capability supported by
+// the kernel is shown as "all".
+func (s *State) String() string {
+	return fmt.Sprintf("effective=%s permitted=%s inheritable=%s bounding=%s ambient=%s",
+		summarizeSet(s.Effective), summarizeSet(s.Permitted), summarizeSet(s.Inheritable),
+		summarizeSet(s.Bounding), summarizeSet(s.Ambient))
+}
+
+// summarizeSet renders caps as a comma separated list of names, "none"
+// or "all".
+func summarizeSet(caps CapSet) string {
+	switch {
+	case caps.IsEmpty():
+		return "none"
+	case caps == AllCaps():
+		return "all"
+	}
+	return FormatCapList(caps)
+}
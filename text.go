@@ -0,0 +1,245 @@
+package capabilities
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Flag bits used by the libcap text representation. The values follow
+// libcap's cap_flag_t order, which determines the order of the clauses.
+const (
+	textEffective   = 1
+	textPermitted   = 2
+	textInheritable = 4
+)
+
+// textFlags returns the libcap flag bits of capability c in s.
+func (s *State) textFlags(c Cap) int {
+	var flags int
+	if s.Effective.Contains(c) {
+		flags |= textEffective
+	}
+	if s.Inheritable.Contains(c) {
+		flags |= textInheritable
+	}
+	if s.Permitted.Contains(c) {
+		flags |= textPermitted
+	}
+	return flags
+}
+
+// flagText renders flag bits in libcap order, e.g. "eip".
+func flagText(flags int) string {
+	var b strings.Builder
+	if flags&textEffective != 0 {
+		b.WriteByte('e')
+	}
+	if flags&textInheritable != 0 {
+		b.WriteByte('i')
+	}
+	if flags&textPermitted != 0 {
+		b.WriteByte('p')
+	}
+	return b.String()
+}
+
+// Text returns the effective, inheritable and permitted sets in the
+// textual representation produced by libcap's cap_to_text(3), e.g.
+// "cap_net_admin,cap_net_raw=eip" or "=ep". The output matches getpcaps
+// and capsh and is accepted by setcap. The bounding and ambient sets are
+// not part of this representation.
+func (s *State) Text() string {
+	// Like libcap, the most common flag combination across all
+	// capabilities becomes the base clause and every other combination
+	// is expressed relative to it.
+	var histo [8]int
+	for c := 0; c <= LastCap(); c++ {
+		histo[s.textFlags(Cap(c))]++
+	}
+	m := 7
+	for t := 6; t >= 0; t-- {
+		if histo[t] >= histo[m] {
+			m = t
+		}
+	}
+	var b strings.Builder
+	b.WriteString("=" + flagText(m))
+	blankBase := m == 0
+	for t := 7; t >= 0; t-- {
+		if t == m || histo[t] == 0 {
+			continue
+		}
+		b.WriteByte(' ')
+		var names []string
+		for c := 0; c <= LastCap(); c++ {
+			if s.textFlags(Cap(c)) == t {
+				names = append(names, CapName(Cap(c)))
+			}
+		}
+		b.WriteString(strings.Join(names, ","))
+		if n := t &^ m; n != 0 {
+			op := "+"
+			if blankBase {
+				// A lone "=" base is folded into the first clause,
+				// giving "cap_x=ep" rather than "= cap_x+ep".
+				op = "="
+			}
+			b.WriteString(op + flagText(n))
+		}
+		if n := m &^ t; n != 0 {
+			b.WriteString("-" + flagText(n))
+		}
+		blankBase = false
+	}
+	text := b.String()
+	if m == 0 && len(text) > 2 {
+		text = text[2:]
+	}
+	return text
+}
+
+// ParseText parses the textual capability representation understood by
+// libcap's cap_from_text(3), e.g. "cap_net_admin,cap_net_raw+eip" or
+// "=ep cap_sys_admin-e", into a State. Clauses are separated by white
+// space; each clause is a comma separated capability list followed by one
+// or more operator (=, + or -) and flag (e, i, p) sequences. An empty list
+// before "=" or the name "all" selects every capability. Capability names
+// are accepted in any form understood by ParseCap.
+//
+// The text only describes the effective, inheritable and permitted sets;
+// the returned State keeps every capability in the bounding set and has
+// an empty ambient set.
+func ParseText(text string) (*State, error) {
+	s := State{Bounding: AllCaps()}
+	if err := s.modifyText(text); err != nil {
+		return nil, err
+	}
+	return &s, nil
+}
+
+// modifyText applies the clauses of a libcap text representation to the
+// effective, inheritable and permitted sets of s.
+func (s *State) modifyText(text string) error {
+	for _, clause := range strings.Fields(text) {
+		if err := s.modifyClause(clause); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (s *State) modifyClause(clause string) error {
+	i := strings.IndexAny(clause, "=+-")
+	if i < 0 {
+		return fmt.Errorf("capability clause %q has no operator", clause)
+	}
+	var caps CapSet
+	if list := clause[:i]; list == "" {
+		if clause[i] != '=' {
+			return fmt.Errorf("capability clause %q has no capabilities", clause)
+		}
+		caps = AllCaps()
+	} else {
+		for _, name := range strings.Split(list, ",") {
+			if strings.EqualFold(name, "all") {
+				caps = caps.Union(AllCaps())
+				continue
+			}
+			c, err := ParseCap(name)
+			if err != nil {
+				return fmt.Errorf("capability clause %q: %w", clause, err)
+			}
+			caps = caps.Add(c)
+		}
+	}
+	for rest := clause[i:]; rest != ""; {
+		op := rest[0]
+		rest = rest[1:]
+		var flags int
+		for rest != "" && !strings.ContainsRune("=+-", rune(rest[0])) {
+			switch rest[0] {
+			case 'e', 'E':
+				flags |= textEffective
+			case 'i', 'I':
+				flags |= textInheritable
+			case 'p', 'P':
+				flags |= textPermitted
+			default:
+				return fmt.Errorf("capability clause %q has invalid flag %q", clause, rest[0])
+			}
+			rest = rest[1:]
+		}
+		if op != '=' && flags == 0 {
+			return fmt.Errorf("capability clause %q has operator %q without flags", clause, op)
+		}
+		switch op {
+		case '=':
+			s.modifyFlags(caps, textEffective|textInheritable|textPermitted, false)
+			s.modifyFlags(caps, flags, true)
+		case '+':
+			s.modifyFlags(caps, flags, true)
+		case '-':
+			s.modifyFlags(caps, flags, false)
+		}
+	}
+	return nil
+}
+
+// modifyFlags raises or lowers caps in the sets selected by flags.
+func (s *State) modifyFlags(caps CapSet, flags int, raise bool) {
+	update := func(set CapSet) CapSet {
+		if raise {
+			return set.Union(caps)
+		}
+		return set.Subtract(caps)
+	}
+	if flags&textEffective != 0 {
+		s.Effective = update(s.Effective)
+	}
+	if flags&textInheritable != 0 {
+		s.Inheritable = update(s.Inheritable)
+	}
+	if flags&textPermitted != 0 {
+		s.Permitted = update(s.Permitted)
+	}
+}
+
+// ApplyText interprets text in the libcap textual representation as a
+// change to the current capability state of the calling thread and
+// applies the result, e.g. ApplyText("cap_net_raw+eip cap_sys_admin-e").
+// Capabilities not named in text keep their current flags. Ambient
+// capabilities that are no longer both permitted and inheritable are
+// dropped, as the kernel would do.
+//
+// As with State.Apply, callers should hold runtime.LockOSThread.
+func ApplyText(text string) error {
+	return withDefault(func(c *Capabilities) error {
+		s, err := c.GetState(0)
+		if err != nil {
+			return err
+		}
+		if err := s.modifyText(text); err != nil {
+			return err
+		}
+		s.Ambient = s.Ambient.Intersect(s.Permitted).Intersect(s.Inheritable)
+		if err := s.Validate(); err != nil {
+			return err
+		}
+		return c.apply(s)
+	})
+}
+
+// FormatGetpcaps renders the capabilities of pid the way getpcaps(8)
+// does, e.g. "1234: cap_net_bind_service=ep".
+func FormatGetpcaps(pid int, s *State) string {
+	return fmt.Sprintf("%d: %s", pid, s.Text())
+}
+
+// Getpcaps returns the getpcaps(8) output line for pid.
+func (c *Capabilities) Getpcaps(pid int) (string, error) {
+	s, err := c.GetState(pid)
+	if err != nil {
+		return "", err
+	}
+	return FormatGetpcaps(pid, s), nil
+}
@@ -0,0 +1,83 @@
+package capabilities
+
+import (
+	"testing"
+)
+
+func TestTextRoundTrip(t *testing.T) {
+	all := AllCaps()
+	net := NewCapSet(CapNetAdmin, CapNetRaw)
+	tests := []struct {
+		name  string
+		state State
+		text  string
+	}{
+		{
+			name: "empty",
+			text: "=",
+		},
+		{
+			name:  "all",
+			state: State{Effective: all, Permitted: all},
+			text:  "=ep",
+		},
+		{
+			name:  "eip",
+			state: State{Effective: net, Permitted: net, Inheritable: net},
+			text:  "cap_net_admin,cap_net_raw=eip",
+		},
+		{
+			name:  "permitted only",
+			state: State{Permitted: NewCapSet(CapNetBindService)},
+			text:  "cap_net_bind_service=p",
+		},
+		{
+			name:  "inheritable and permitted",
+			state: State{Inheritable: NewCapSet(CapNetBindService), Permitted: NewCapSet(CapNetBindService)},
+			text:  "cap_net_bind_service=ip",
+		},
+		{
+			name:  "all but one effective",
+			state: State{Effective: all.Remove(CapSysAdmin), Permitted: all},
+			text:  "=ep cap_sys_admin-e",
+		},
+		{
+			name: "mixed",
+			state: State{
+				Effective:   NewCapSet(CapChown),
+				Permitted:   NewCapSet(CapChown, CapKill),
+				Inheritable: NewCapSet(CapKill),
+			},
+			text: "cap_kill=ip cap_chown+ep",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.state.Bounding = all
+			if got := tt.state.Text(); got != tt.text {
+				t.Errorf("Text = %q, want %q", got, tt.text)
+			}
+			s, err := ParseText(tt.text)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if *s != tt.state {
+				t.Errorf("ParseText(%q) = %v, want %v", tt.text, s, &tt.state)
+			}
+		})
+	}
+}
+
+func TestParseTextErrors(t *testing.T) {
+	for _, text := range []string{
+		"cap_chown",
+		"+e",
+		"cap_chown+",
+		"cap_chown=x",
+		"cap_bogus=e",
+	} {
+		if s, err := ParseText(text); err == nil {
+			t.Errorf("ParseText(%q) = %v, want error", text, s)
+		}
+	}
+}
@@ -0,0 +1,93 @@
+package capabilities
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+)
+
+// Thread is the capability state of one thread of a process.
+//
+// Capabilities, the bounding set, securebits and no_new_privs are
+// per-thread attributes, so the threads of a process can hold different
+// sets. A Go program already runs several threads when main starts and
+// the runtime moves goroutines between them, so a per-thread change such
+// as State.Apply, DropBounding, SetSecurebits or SetNoNewPrivs affects
+// whichever thread the goroutine happens to run on. Threads the runtime
+// starts later are cloned from an arbitrary thread and may or may not
+// inherit the change, except while the creating goroutine holds
+// runtime.LockOSThread, when they are cloned from a thread that kept the
+// initial state. Callers of per-thread functions should therefore hold
+// runtime.LockOSThread while changing and relying on the state, and can
+// return from the goroutine without unlocking to discard the thread.
+//
+// To change the whole process use the all-threads variants, e.g.
+// DropBoundingAllThreads, SetSecurebitsAllThreads,
+// SetNoNewPrivsAllThreads or DropPrivileges. They rely on
+// syscall.AllThreadsSyscall, which is not available in programs built
+// with cgo and fails with unix.ENOTSUP there. DivergentThreads finds
+// threads left behind.
+type Thread struct {
+	Tid   int
+	State State
+}
+
+// Threads returns the capability state of every thread of pid, read from
+// /proc/<pid>/task/<tid>/status, sorted by thread ID. Threads that exit
+// while they are read are omitted.
+func (c *Capabilities) Threads(pid int) ([]Thread, error) {
+	taskDir := c.procPath(pid, "task")
+	entries, err := os.ReadDir(taskDir)
+	if err != nil {
+		return nil, newError("read tasks", pid, err)
+	}
+	var threads []Thread
+	for _, e := range entries {
+		tid, err := strconv.Atoi(e.Name())
+		if err != nil {
+			continue
+		}
+		status, err := os.ReadFile(filepath.Join(taskDir, e.Name(), "status"))
+		if err != nil {
+			continue
+		}
+		s, err := ParseProcStatus(bytes.NewReader(status))
+		if err != nil {
+			return nil, newError("read status", tid, err)
+		}
+		threads = append(threads, Thread{Tid: tid, State: *s})
+	}
+	sort.Slice(threads, func(i, j int) bool {
+		return threads[i].Tid < threads[j].Tid
+	})
+	return threads, nil
+}
+
+// DivergentThreads returns the threads of pid whose capability state
+// differs from that of the thread group leader (the thread whose ID is
+// pid, or the lowest remaining thread ID if the leader has exited). An
+// empty result means all threads agree. Divergent threads are a common
+// source of privilege bugs, e.g. when capabilities are dropped on one
+// thread of a Go program while other threads keep them.
+func (c *Capabilities) DivergentThreads(pid int) ([]Thread, error) {
+	threads, err := c.Threads(pid)
+	if err != nil || len(threads) == 0 {
+		return nil, err
+	}
+	leader := threads[0]
+	for _, t := range threads {
+		if t.Tid == pid {
+			leader = t
+			break
+		}
+	}
+	var divergent []Thread
+	for _, t := range threads {
+		if t.State != leader.State {
+			divergent = append(divergent, t)
+		}
+	}
+	return divergent, nil
+}
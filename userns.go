@@ -0,0 +1,127 @@
+package capabilities
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"golang.org/x/sys/unix"
+)
+
+// initUserNSInode is the inode number of the initial user namespace
+// (PROC_USER_INIT_INO in linux/proc_ns.h).
+const initUserNSInode = 0xeffffffd
+
+// IDMapping is a line of /proc/<pid>/uid_map or gid_map: Count IDs
+// starting at ID inside the namespace correspond to the IDs starting at
+// HostID in the parent namespace.
+type IDMapping struct {
+	ID     uint32
+	HostID uint32
+	Count  uint32
+}
+
+// UserNamespace describes the user namespace of a process.
+// Capabilities only grant privileges over resources owned by the user
+// namespace they are held in and its descendants, so CAP_SYS_ADMIN in a
+// namespace other than the initial one does not reach the host.
+type UserNamespace struct {
+	// Inode identifies the namespace, as in the user:[<inode>] link
+	// of /proc/<pid>/ns/user.
+	Inode uint64
+	// Initial is true for the initial user namespace.
+	Initial bool
+	// OwnerUID is the user ID, in the namespace of the caller, of the
+	// creator of the namespace, or -1 if it could not be determined.
+	OwnerUID int
+	// UIDMap and GIDMap are the ID mappings to the parent namespace.
+	UIDMap []IDMapping
+	GIDMap []IDMapping
+}
+
+// UserNamespace returns the user namespace of pid. A pid of 0 refers to
+// the calling thread.
+func (c *Capabilities) UserNamespace(pid int) (*UserNamespace, error) {
+	path := func(name string) string {
+		if pid == 0 {
+			return filepath.Join(defaultProcRoot, "thread-self", name)
+		}
+		return c.procPath(pid, name)
+	}
+	link, err := os.Readlink(path("ns/user"))
+	if err != nil {
+		return nil, newError("read user namespace", pid, err)
+	}
+	var ns UserNamespace
+	if _, err := fmt.Sscanf(link, "user:[%d]", &ns.Inode); err != nil {
+		return nil, fmt.Errorf("parse user namespace %q: %w", link, err)
+	}
+	ns.Initial = ns.Inode == initUserNSInode
+	ns.OwnerUID = -1
+	if f, err := os.Open(path("ns/user")); err == nil {
+		if uid, err := unix.IoctlGetUint32(int(f.Fd()), unix.NS_GET_OWNER_UID); err == nil {
+			ns.OwnerUID = int(uid)
+		}
+		f.Close()
+	}
+	if ns.UIDMap, err = readIDMap(path("uid_map")); err != nil {
+		return nil, newError("read uid_map", pid, err)
+	}
+	if ns.GIDMap, err = readIDMap(path("gid_map")); err != nil {
+		return nil, newError("read gid_map", pid, err)
+	}
+	return &ns, nil
+}
+
+// readIDMap parses a uid_map or gid_map file.
+func readIDMap(path string) ([]IDMapping, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	var mappings []IDMapping
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		fields := strings.Fields(scanner.Text())
+		if len(fields) != 3 {
+			continue
+		}
+		var ids [3]uint32
+		for i, field := range fields {
+			id, err := strconv.ParseUint(field, 10, 32)
+			if err != nil {
+				return nil, fmt.Errorf("parse %s: %w", path, err)
+			}
+			ids[i] = uint32(id)
+		}
+		mappings = append(mappings, IDMapping{ID: ids[0], HostID: ids[1], Count: ids[2]})
+	}
+	return mappings, scanner.Err()
+}
+
+// HostUID maps uid inside the namespace to the corresponding user ID
+// outside it. It returns false if uid is not mapped.
+func (ns *UserNamespace) HostUID(uid uint32) (uint32, bool) {
+	for _, m := range ns.UIDMap {
+		if uint64(uid) >= uint64(m.ID) && uint64(uid) < uint64(m.ID)+uint64(m.Count) {
+			return uid - m.ID + m.HostID, true
+		}
+	}
+	return 0, false
+}
+
+// NamespaceUID maps the user ID hostUID outside the namespace to the
+// corresponding user ID inside it. It returns false if hostUID is not
+// mapped.
+func (ns *UserNamespace) NamespaceUID(hostUID uint32) (uint32, bool) {
+	for _, m := range ns.UIDMap {
+		if uint64(hostUID) >= uint64(m.HostID) && uint64(hostUID) < uint64(m.HostID)+uint64(m.Count) {
+			return hostUID - m.HostID + m.ID, true
+		}
+	}
+	return 0, false
+}
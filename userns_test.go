@@ -0,0 +1,97 @@
+package capabilities
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestReadIDMap(t *testing.T) {
+	tests := []struct {
+		name    string
+		data    string
+		want    []IDMapping
+		wantErr bool
+	}{
+		{
+			name: "initial namespace",
+			data: "         0          0 4294967295\n",
+			want: []IDMapping{{ID: 0, HostID: 0, Count: 4294967295}},
+		},
+		{
+			name: "several ranges",
+			data: "0 1000 1\n1 100000 65536\n",
+			want: []IDMapping{
+				{ID: 0, HostID: 1000, Count: 1},
+				{ID: 1, HostID: 100000, Count: 65536},
+			},
+		},
+		{
+			name: "unmapped",
+			data: "",
+		},
+		{
+			name:    "malformed",
+			data:    "0 x 1\n",
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), "uid_map")
+			if err := os.WriteFile(path, []byte(tt.data), 0o644); err != nil {
+				t.Fatal(err)
+			}
+			got, err := readIDMap(path)
+			if tt.wantErr {
+				if err == nil {
+					t.Fatalf("readIDMap = %v, want error", got)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("readIDMap = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestHostUID(t *testing.T) {
+	ns := &UserNamespace{UIDMap: []IDMapping{
+		{ID: 0, HostID: 1000, Count: 1},
+		{ID: 1, HostID: 100000, Count: 65536},
+	}}
+	full := &UserNamespace{UIDMap: []IDMapping{{ID: 0, HostID: 0, Count: 4294967295}}}
+	tests := []struct {
+		name   string
+		ns     *UserNamespace
+		uid    uint32
+		host   uint32
+		mapped bool
+	}{
+		{"root", ns, 0, 1000, true},
+		{"first of range", ns, 1, 100000, true},
+		{"last of range", ns, 65536, 165535, true},
+		{"past range", ns, 65537, 0, false},
+		{"identity", full, 4294967294, 4294967294, true},
+		{"no mapping", &UserNamespace{}, 0, 0, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			host, ok := tt.ns.HostUID(tt.uid)
+			if host != tt.host || ok != tt.mapped {
+				t.Fatalf("HostUID(%d) = %d, %v, want %d, %v", tt.uid, host, ok, tt.host, tt.mapped)
+			}
+			if !ok {
+				return
+			}
+			if uid, ok := tt.ns.NamespaceUID(host); uid != tt.uid || !ok {
+				t.Errorf("NamespaceUID(%d) = %d, %v, want %d, true", host, uid, ok, tt.uid)
+			}
+		})
+	}
+}
@@ -0,0 +1,112 @@
+package capabilities
+
+import (
+	"context"
+	"fmt"
+	"time"
+)
+
+const defaultWatchInterval = time.Second
+
+// Change is a capability change observed by Watch.
+type Change struct {
+	Pid  int
+	Time time.Time
+	// Old and New are the capability states before and after the change.
+	Old State
+	New State
+	// Err is set on the last event sent when the process can no longer
+	// be read, typically because it exited. Old and New then both hold
+	// the last known state.
+	Err error
+}
+
+// Gained returns the capabilities added to each set.
+func (ch *Change) Gained() State {
+	return State{
+		Effective:   ch.New.Effective.Subtract(ch.Old.Effective),
+		Permitted:   ch.New.Permitted.Subtract(ch.Old.Permitted),
+		Inheritable: ch.New.Inheritable.Subtract(ch.Old.Inheritable),
+		Bounding:    ch.New.Bounding.Subtract(ch.Old.Bounding),
+		Ambient:     ch.New.Ambient.Subtract(ch.Old.Ambient),
+	}
+}
+
+// Lost returns the capabilities removed from each set.
+func (ch *Change) Lost() State {
+	return State{
+		Effective:   ch.Old.Effective.Subtract(ch.New.Effective),
+		Permitted:   ch.Old.Permitted.Subtract(ch.New.Permitted),
+		Inheritable: ch.Old.Inheritable.Subtract(ch.New.Inheritable),
+		Bounding:    ch.Old.Bounding.Subtract(ch.New.Bounding),
+		Ambient:     ch.Old.Ambient.Subtract(ch.New.Ambient),
+	}
+}
+
+// WatchOption configures Watch.
+type WatchOption func(*watchOptions)
+
+type watchOptions struct {
+	interval time.Duration
+}
+
+// WithInterval sets how often Watch polls the process. The default is
+// one second. The interval must be positive.
+func WithInterval(d time.Duration) WatchOption {
+	return func(o *watchOptions) {
+		o.interval = d
+	}
+}
+
+// Watch polls the capability state of pid from /proc/<pid>/status and
+// sends a Change on the returned channel whenever it differs from the
+// previous poll. The channel is closed when ctx is done or after an event
+// carrying Err when the process can no longer be read. An error is
+// returned if the interval is not positive or the initial state cannot be
+// read.
+func (c *Capabilities) Watch(ctx context.Context, pid int, opts ...WatchOption) (<-chan Change, error) {
+	o := watchOptions{interval: defaultWatchInterval}
+	for _, opt := range opts {
+		opt(&o)
+	}
+	if o.interval <= 0 {
+		return nil, fmt.Errorf("invalid watch interval %v", o.interval)
+	}
+	s, err := c.ReadProcStatus(pid)
+	if err != nil {
+		return nil, err
+	}
+	changes := make(chan Change)
+	go func() {
+		defer close(changes)
+		last := *s
+		ticker := time.NewTicker(o.interval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+			}
+			ch := Change{Pid: pid, Time: time.Now(), Old: last, New: last}
+			s, err := c.ReadProcStatus(pid)
+			if err != nil {
+				ch.Err = err
+			} else if *s == last {
+				continue
+			} else {
+				ch.New = *s
+				last = *s
+			}
+			select {
+			case changes <- ch:
+			case <-ctx.Done():
+				return
+			}
+			if ch.Err != nil {
+				return
+			}
+		}
+	}()
+	return changes, nil
+}
@@ -0,0 +1,166 @@
+package capabilities
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"sync"
+)
+
+// workerEnv marks a process started by StartWorker.
+const workerEnv = "CAPABILITIES_WORKER_FD"
+
+// Worker is an unprivileged child process started by StartWorker. Risky
+// work such as parsing untrusted input is sent to it with Call and runs
+// with an empty capability set.
+type Worker struct {
+	mu   sync.Mutex
+	conn *os.File
+	cmd  *exec.Cmd
+	enc  *json.Encoder
+	dec  *json.Decoder
+}
+
+// workerResponse is the reply to a request sent by Call.
+type workerResponse struct {
+	Result json.RawMessage `json:"result,omitempty"`
+	Error  string          `json:"error,omitempty"`
+}
+
+// WorkerHandler handles a request sent with Worker.Call. The request is
+// passed as raw JSON; the result is encoded as JSON and returned to the
+// caller, and a non-nil error is returned to the caller as an error.
+type WorkerHandler func(req json.RawMessage) (interface{}, error)
+
+// StartWorker re-executes the current binary as a worker child with no
+// capabilities and connects to it over a socket pair. Requests and
+// responses are exchanged as JSON.
+//
+// The child runs with the same arguments. main must call ServeWorker
+// early; in the worker it serves requests until the parent closes the
+// connection.
+//
+// The worker is started by a Launcher keeping no capabilities. If the
+// caller holds CAP_SETPCAP the bounding set of the worker is emptied as
+// well. ServeWorker clears the capability sets of the worker again before
+// serving, so that a worker that still holds capabilities, e.g. because it
+// runs as root without a reduced bounding set, never runs the handler.
+func StartWorker() (*Worker, error) {
+	setpcap, err := Has(CapSetpcap)
+	if err != nil {
+		return nil, err
+	}
+	conn, cmd, err := startHelper(workerEnv, &Launcher{DropBounding: setpcap}, 0)
+	if err != nil {
+		return nil, err
+	}
+	return &Worker{
+		conn: conn,
+		cmd:  cmd,
+		enc:  json.NewEncoder(conn),
+		dec:  json.NewDecoder(conn),
+	}, nil
+}
+
+// Call sends req to the worker and decodes its result into resp, which
+// may be nil to discard it. Errors returned by the handler in the worker
+// are returned as errors. Calls are serialized.
+func (w *Worker) Call(req, resp interface{}) error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if err := w.enc.Encode(req); err != nil {
+		return err
+	}
+	var r workerResponse
+	if err := w.dec.Decode(&r); err != nil {
+		if err == io.EOF {
+			err = io.ErrUnexpectedEOF
+		}
+		return err
+	}
+	if r.Error != "" {
+		return errors.New(r.Error)
+	}
+	if resp == nil || r.Result == nil {
+		return nil
+	}
+	return json.Unmarshal(r.Result, resp)
+}
+
+// Process returns the worker process.
+func (w *Worker) Process() *os.Process {
+	return w.cmd.Process
+}
+
+// Close closes the connection, which makes ServeWorker return in the
+// worker, and waits for the worker to exit.
+func (w *Worker) Close() error {
+	return closeHelper(w.conn, w.cmd)
+}
+
+// ServeWorker returns false immediately if the calling process is not a
+// worker started by StartWorker. In a worker it clears the effective,
+// permitted, inheritable and ambient sets of every thread, then calls
+// handler for every request until the parent closes the connection and
+// returns true; the program should then exit. A malformed request is
+// answered with an error and ends serving as well. If any capability remains
+// the worker fails closed: handler is never called and every request is
+// answered with an error.
+func ServeWorker(handler WorkerHandler) bool {
+	conn, ok := helperConn(workerEnv)
+	if !ok {
+		return false
+	}
+	defer conn.Close()
+	dropErr := dropWorker()
+	enc := json.NewEncoder(conn)
+	dec := json.NewDecoder(conn)
+	for {
+		var req json.RawMessage
+		if err := dec.Decode(&req); err != nil {
+			if err != io.EOF {
+				// The stream cannot be resynchronized after a
+				// malformed request, so report it and stop.
+				enc.Encode(&workerResponse{Error: fmt.Sprintf("worker: decode request: %v", err)})
+			}
+			return true
+		}
+		var r workerResponse
+		var result interface{}
+		err := dropErr
+		if err == nil {
+			result, err = handler(req)
+		}
+		if err == nil {
+			r.Result, err = json.Marshal(result)
+		}
+		if err != nil {
+			r.Error = err.Error()
+		}
+		if err := enc.Encode(&r); err != nil {
+			return true
+		}
+	}
+}
+
+// dropWorker clears the effective, permitted, inheritable and ambient sets
+// of every thread and confirms with GetState that none remain.
+func dropWorker() error {
+	if err := dropAllThreads(); err != nil {
+		return fmt.Errorf("worker: %w", err)
+	}
+	return withDefault(func(c *Capabilities) error {
+		s, err := c.GetState(0)
+		if err != nil {
+			return fmt.Errorf("worker: %w", err)
+		}
+		left := s.Effective.Union(s.Permitted).Union(s.Inheritable).Union(s.Ambient)
+		if !left.IsEmpty() {
+			return fmt.Errorf("worker: capabilities %v not dropped", left)
+		}
+		return nil
+	})
+}
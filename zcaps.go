@@ -0,0 +1,96 @@
+// Code generated by mkcaps.go; DO NOT EDIT.
+
+package capabilities
+
+// Capabilities known to this package, as defined in linux/capability.h.
+const (
+	CapChown             Cap = 0
+	CapDacOverride       Cap = 1
+	CapDacReadSearch     Cap = 2
+	CapFowner            Cap = 3
+	CapFsetid            Cap = 4
+	CapKill              Cap = 5
+	CapSetgid            Cap = 6
+	CapSetuid            Cap = 7
+	CapSetpcap           Cap = 8
+	CapLinuxImmutable    Cap = 9
+	CapNetBindService    Cap = 10
+	CapNetBroadcast      Cap = 11
+	CapNetAdmin          Cap = 12
+	CapNetRaw            Cap = 13
+	CapIpcLock           Cap = 14
+	CapIpcOwner          Cap = 15
+	CapSysModule         Cap = 16
+	CapSysRawio          Cap = 17
+	CapSysChroot         Cap = 18
+	CapSysPtrace         Cap = 19
+	CapSysPacct          Cap = 20
+	CapSysAdmin          Cap = 21
+	CapSysBoot           Cap = 22
+	CapSysNice           Cap = 23
+	CapSysResource       Cap = 24
+	CapSysTime           Cap = 25
+	CapSysTtyConfig      Cap = 26
+	CapMknod             Cap = 27
+	CapLease             Cap = 28
+	CapAuditWrite        Cap = 29
+	CapAuditControl      Cap = 30
+	CapSetfcap           Cap = 31
+	CapMacOverride       Cap = 32
+	CapMacAdmin          Cap = 33
+	CapSyslog            Cap = 34
+	CapWakeAlarm         Cap = 35
+	CapBlockSuspend      Cap = 36
+	CapAuditRead         Cap = 37
+	CapPerfmon           Cap = 38
+	CapBpf               Cap = 39
+	CapCheckpointRestore Cap = 40
+)
+
+// lastKnownCap is the highest capability defined in linux/capability.h.
+const lastKnownCap = CapCheckpointRestore
+
+// capNames holds the libcap name of each capability.
+var capNames = [...]string{
+	CapChown:             "cap_chown",
+	CapDacOverride:       "cap_dac_override",
+	CapDacReadSearch:     "cap_dac_read_search",
+	CapFowner:            "cap_fowner",
+	CapFsetid:            "cap_fsetid",
+	CapKill:              "cap_kill",
+	CapSetgid:            "cap_setgid",
+	CapSetuid:            "cap_setuid",
+	CapSetpcap:           "cap_setpcap",
+	CapLinuxImmutable:    "cap_linux_immutable",
+	CapNetBindService:    "cap_net_bind_service",
+	CapNetBroadcast:      "cap_net_broadcast",
+	CapNetAdmin:          "cap_net_admin",
+	CapNetRaw:            "cap_net_raw",
+	CapIpcLock:           "cap_ipc_lock",
+	CapIpcOwner:          "cap_ipc_owner",
+	CapSysModule:         "cap_sys_module",
+	CapSysRawio:          "cap_sys_rawio",
+	CapSysChroot:         "cap_sys_chroot",
+	CapSysPtrace:         "cap_sys_ptrace",
+	CapSysPacct:          "cap_sys_pacct",
+	CapSysAdmin:          "cap_sys_admin",
+	CapSysBoot:           "cap_sys_boot",
+	CapSysNice:           "cap_sys_nice",
+	CapSysResource:       "cap_sys_resource",
+	CapSysTime:           "cap_sys_time",
+	CapSysTtyConfig:      "cap_sys_tty_config",
+	CapMknod:             "cap_mknod",
+	CapLease:             "cap_lease",
+	CapAuditWrite:        "cap_audit_write",
+	CapAuditControl:      "cap_audit_control",
+	CapSetfcap:           "cap_setfcap",
+	CapMacOverride:       "cap_mac_override",
+	CapMacAdmin:          "cap_mac_admin",
+	CapSyslog:            "cap_syslog",
+	CapWakeAlarm:         "cap_wake_alarm",
+	CapBlockSuspend:      "cap_block_suspend",
+	CapAuditRead:         "cap_audit_read",
+	CapPerfmon:           "cap_perfmon",
+	CapBpf:               "cap_bpf",
+	CapCheckpointRestore: "cap_checkpoint_restore",
+}